@@ -0,0 +1,194 @@
+// Package cli implements the `hardwire` subcommands. It's meant to be
+// called from the app's `main`, after the configuration and all the
+// resources and actions have been registered, so that the subcommands
+// operate on the same setup the server would:
+//
+//	func main() {
+//		server := echo.New()
+//		hardwire.Configure(&hardwire.Configuration{...})
+//		registerResources()
+//
+//		if cli.Main(server) {
+//			return
+//		}
+//
+//		hardwire.UseWith(server)
+//		server.Logger.Fatal(server.Start(":8080"))
+//	}
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire"
+	config "github.com/ncpa0/hardwire/configuration"
+	templatebuilder "github.com/ncpa0/hardwire/template-builder"
+	"github.com/ncpa0/hardwire/views"
+)
+
+const usage = `Usage: %s <command> [options]
+
+Commands:
+  build    build the pages and write the artifact bundle
+  routes   print all the routes served by hardwire
+  check    validate the views metadata against registered resources and actions
+  serve    start the development server
+`
+
+var ErrUnknownCommand = errors.New("unknown command")
+
+type command struct {
+	name string
+	run  func(server *echo.Echo, args []string, out io.Writer) error
+}
+
+var commands = []command{
+	{name: "build", run: runBuild},
+	{name: "routes", run: runRoutes},
+	{name: "check", run: runCheck},
+	{name: "serve", run: runServe},
+}
+
+// Runs the subcommand given in the process arguments, if any, and exits
+// the process with a non-zero code when it fails. Returns false when no
+// hardwire subcommand was given, so that the app can proceed with its
+// usual startup.
+func Main(server *echo.Echo) bool {
+	if len(os.Args) < 2 || !IsCommand(os.Args[1]) {
+		return false
+	}
+
+	err := Run(server, os.Args[1:], os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	return true
+}
+
+func IsCommand(name string) bool {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return true
+		}
+	}
+	return name == "help"
+}
+
+// Runs the given subcommand, `args[0]` being the subcommand name.
+func Run(server *echo.Echo, args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] == "help" {
+		fmt.Fprintf(out, usage, "hardwire")
+		return nil
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(server, args[1:], out)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+}
+
+func runBuild(server *echo.Echo, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	clean := flags.Bool("clean", config.Current.CleanBuild, "clean the html directory before building")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	config.Current.CleanBuild = *clean
+
+	err = hardwire.Build()
+	if err != nil {
+		var buildErr *templatebuilder.BuildError
+		if errors.As(err, &buildErr) {
+			buildErr.Report.Print(os.Stderr, false)
+			return errors.New("build failed")
+		}
+		return err
+	}
+
+	fmt.Fprintf(out, "Pages built into %s\n", config.Current.HtmlDir)
+	return nil
+}
+
+func loadBuiltViews() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	return views.LoadBuiltViews(wd)
+}
+
+func runRoutes(server *echo.Echo, args []string, out io.Writer) error {
+	err := loadBuiltViews()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tMETHOD\tPATH\tRESOURCES\tCACHING")
+	for _, route := range hardwire.Routes() {
+		resources := strings.Join(route.Resources, ", ")
+		if resources == "" {
+			resources = "-"
+		}
+		caching := route.Caching
+		if caching == "" {
+			caching = "-"
+		}
+		fmt.Fprintf(
+			tw, "%s\t%s\t%s\t%s\t%s\n",
+			route.Kind, route.Method, route.Path, resources, caching,
+		)
+	}
+
+	return tw.Flush()
+}
+
+func runCheck(server *echo.Echo, args []string, out io.Writer) error {
+	err := loadBuiltViews()
+	if err != nil {
+		return err
+	}
+
+	err = hardwire.Check()
+	if err != nil {
+		return fmt.Errorf("check failed:\n%w", err)
+	}
+
+	fmt.Fprintln(out, "No problems found")
+	return nil
+}
+
+func runServe(server *echo.Echo, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", ":8080", "address the server will listen on")
+	debug := flags.Bool("debug", true, "print debug information")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	config.Current.DebugMode = *debug
+	config.Current.Prebuilt = false
+	config.Current.NoBuild = false
+
+	err = hardwire.UseWith(server)
+	if err != nil {
+		return err
+	}
+
+	return server.Start(*addr)
+}
@@ -0,0 +1,51 @@
+// The `hardwire` command runs the hardwire subcommands of a Go project.
+//
+// Resources, actions and the configuration are registered by the project's
+// own code, so this command runs the project (`go run <package> <command>`),
+// which is expected to hand its arguments over to `cli.Main`.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/ncpa0/hardwire/cli"
+)
+
+func main() {
+	flags := flag.NewFlagSet("hardwire", flag.ExitOnError)
+	pkg := flags.String("pkg", ".", "the main package of the project")
+	flags.Usage = func() {
+		cli.Run(nil, []string{"help"}, flags.Output())
+		fmt.Fprintf(flags.Output(), "\nOptions:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(os.Args[1:])
+
+	args := flags.Args()
+	if len(args) == 0 || args[0] == "help" {
+		flags.Usage()
+		return
+	}
+	if !cli.IsCommand(args[0]) {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", cli.ErrUnknownCommand.Error(), args[0])
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cmd := exec.Command("go", append([]string{"run", *pkg}, args...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+}
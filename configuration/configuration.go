@@ -25,6 +25,197 @@ type CachingConfig struct {
 	Fragments     *CachingPolicy
 }
 
+const (
+	RuntimeBun  = "bun"
+	RuntimeNode = "node"
+	RuntimeDeno = "deno"
+)
+
+type BuilderConfig struct {
+	// The JavaScript runtime used to install and run the html generator,
+	// one of `bun`, `node` (20.6 or newer, the TypeScript sources are run
+	// with `tsx`) or `deno`.
+	//
+	// Defaults to `bun`.
+	Runtime string
+	// Overrides the version of the `hardwire-html-generator` package
+	// that gets installed and used to build the pages.
+	//
+	// Defaults to the version this release of Hardwire was made for.
+	GeneratorVersion string
+	// When enabled, the dependencies are installed strictly from the
+	// lockfile, and the build fails if the lockfile is missing or
+	// doesn't match the `package.json`.
+	FrozenLockfile bool
+	// When enabled, nothing is installed and no package registry is ever
+	// contacted, the generator and its dependencies must already be
+	// present in the `node_modules` directory next to the entrypoint.
+	Offline bool
+}
+
+type RealtimeConfig struct {
+	// The URL path of the endpoint pages can subscribe to, to receive
+	// island updates as Server-Sent Events.
+	//
+	// Defaults to `/__hardwire/subscribe`.
+	SubscribeURL string
+	// How often a heartbeat is sent over idle subscriptions.
+	//
+	// Defaults to 15 seconds.
+	HeartbeatInterval time.Duration
+	// How long the clients should wait before reconnecting after the
+	// connection is lost.
+	//
+	// Defaults to 3 seconds.
+	ReconnectDelay time.Duration
+	// How many of the most recent events are kept for each client, to
+	// be replayed when it reconnects.
+	//
+	// Defaults to 50.
+	ReplayBufferSize int
+	// How long the events are kept for a disconnected client.
+	//
+	// Defaults to 1 minute.
+	ReplayTTL time.Duration
+	// When enabled, actions can be performed over a WebSocket connection,
+	// with the resulting island updates streamed back over it.
+	//
+	// Defaults to `false`.
+	WebSocket bool
+	// The URL path of the WebSocket endpoint.
+	//
+	// Defaults to `/__hardwire/ws`.
+	WebSocketURL string
+	// How many action invocations can be queued on a single connection
+	// before the server stops reading from it.
+	//
+	// Defaults to 16.
+	WebSocketQueueSize int
+}
+
+type PaginationConfig struct {
+	// Number of items per page, used when the request doesn't
+	// specify the `limit`.
+	//
+	// Defaults to 25.
+	DefaultLimit int
+	// The maximum number of items per page a request can ask for.
+	//
+	// Defaults to 100.
+	MaxLimit int
+	// What triggers loading of the next page of a paginated list, either
+	// `revealed` (infinite scroll) or `click` (a "load more" button).
+	//
+	// Defaults to `revealed`.
+	Trigger string
+	// Label of the "load more" button, used with the `click` trigger.
+	//
+	// Defaults to `Load more`.
+	LoadMoreLabel string
+}
+
+type CSRFConfig struct {
+	// Disables the CSRF protection of the action endpoints.
+	//
+	// Defaults to `false`.
+	Disabled bool
+	// Key used to sign the tokens, it must be the same on all the server
+	// instances.
+	//
+	// Defaults to a random key generated on startup.
+	Secret []byte
+	// How long an issued token stays valid, the client asks for a new one
+	// shortly before it expires.
+	//
+	// Defaults to 12 hours.
+	TokenTTL time.Duration
+	// Name of the cookie the tokens are bound to.
+	//
+	// Defaults to `__hw_csrf`.
+	CookieName string
+	// Name of the request header carrying the token.
+	//
+	// Defaults to `X-Csrf-Token`.
+	HeaderName string
+	// Name of the form field carrying the token, used by requests
+	// that don't send the header.
+	//
+	// Defaults to `_csrf`.
+	FieldName string
+	// Endpoint issuing the tokens. Pages don't carry the token (they can
+	// be cached), a script on the page fetches it from here instead.
+	//
+	// Defaults to `/__hardwire/csrf`.
+	TokenURL string
+	// Name of the cookie holding the current token, readable by the
+	// script on the page.
+	//
+	// Defaults to `__hw_csrf_token`.
+	TokenCookieName string
+}
+
+type AuthConfig struct {
+	// Resolves the identity of the client making the request (e.g. the
+	// logged in user), shared by all the guards. It's resolved at most
+	// once per request, the first time a guard asks for it.
+	Identity func(c echo.Context) (interface{}, error)
+	// Where to redirect the clients denied with the `401 Unauthorized`
+	// status (e.g. the login page), instead of responding with it.
+	UnauthorizedRedirect string
+	// Where to redirect the clients denied with the `403 Forbidden`
+	// status, instead of responding with it.
+	ForbiddenRedirect string
+}
+
+type RateLimitConfig struct {
+	// How many requests a single client can make to the action endpoints
+	// within the `Window`, across all the actions. Limits of single actions
+	// can be set with `Action.RateLimit()`.
+	//
+	// Defaults to 0 (no limit).
+	Requests int
+	// Defaults to 1 minute.
+	Window time.Duration
+	// The maximum size of an action request body, in bytes.
+	//
+	// Defaults to 10 MiB.
+	MaxBodySize int64
+	// How many actions can be performed at the same time, across all
+	// the clients.
+	//
+	// Defaults to 0 (no limit).
+	MaxConcurrent int
+	// Returns the key identifying the client the limits are applied to
+	// (also used to scope the idempotency keys).
+	//
+	// Defaults to the client IP address, taken from the connection unless
+	// the echo server has an `IPExtractor` set. Behind a proxy, set the
+	// `IPExtractor` (e.g. `echo.ExtractIPFromXFFHeader()`) so that the
+	// forwarded address is used, it's never trusted otherwise.
+	ClientKey func(c echo.Context) string
+}
+
+type IdempotencyConfig struct {
+	// How long the responses are kept, duplicates of a request received
+	// within this time get the response of the first one.
+	//
+	// Defaults to 10 minutes.
+	TTL time.Duration
+	// Name of the request header carrying the idempotency key.
+	//
+	// Defaults to `Idempotency-Key`.
+	HeaderName string
+	// Name of the form field carrying the idempotency key.
+	//
+	// Defaults to `_idempotency_key`.
+	FieldName string
+	// Disables the script sending idempotency keys along with the forms
+	// submitted to the action endpoints.
+	//
+	// Defaults to `false`.
+	DisableFormKeys bool
+}
+
 type Configuration struct {
 	// When enabled, the `.html` extension will be keeped in the URL pathnames
 	// (e.x. route `/home` will be hosted under `https://some.domain/home.html`).
@@ -54,10 +245,24 @@ type Configuration struct {
 	//
 	// Defaults to `false`.
 	NoBuild bool
+	// Skip the html generation step and only load the artifact bundle
+	// produced beforehand by `hardwire.Build()`. The bundle is verified
+	// against its manifest, the server will refuse to start if it's
+	// incomplete or stale.
+	//
+	// Defaults to `false`.
+	Prebuilt bool
 	// Clean the html directory before generating the html files.
 	//
 	// Defaults to `false`.
 	CleanBuild           bool
+	Builder              *BuilderConfig
+	Realtime             *RealtimeConfig
+	Pagination           *PaginationConfig
+	CSRF                 *CSRFConfig
+	Auth                 *AuthConfig
+	RateLimit            *RateLimitConfig
+	Idempotency          *IdempotencyConfig
 	Caching              *CachingConfig
 	BeforeStaticResponse func(resp *servestatic.StaticResponse, c echo.Context) error
 	BeforeResponse       func(c echo.Context) error
@@ -71,9 +276,51 @@ var Current *Configuration = &Configuration{
 	StaticDir:            "static",
 	StaticURL:            "/static",
 	NoBuild:              false,
+	Prebuilt:             false,
 	CleanBuild:           false,
 	BeforeStaticResponse: nil,
 	BeforeResponse:       nil,
+	Builder: &BuilderConfig{
+		Runtime: RuntimeBun,
+	},
+	Realtime: &RealtimeConfig{
+		SubscribeURL:       "/__hardwire/subscribe",
+		HeartbeatInterval:  15 * time.Second,
+		ReconnectDelay:     3 * time.Second,
+		ReplayBufferSize:   50,
+		ReplayTTL:          time.Minute,
+		WebSocket:          false,
+		WebSocketURL:       "/__hardwire/ws",
+		WebSocketQueueSize: 16,
+	},
+	Pagination: &PaginationConfig{
+		DefaultLimit:  25,
+		MaxLimit:      100,
+		Trigger:       "revealed",
+		LoadMoreLabel: "Load more",
+	},
+	CSRF: &CSRFConfig{
+		Disabled:        false,
+		TokenTTL:        12 * time.Hour,
+		CookieName:      "__hw_csrf",
+		HeaderName:      "X-Csrf-Token",
+		FieldName:       "_csrf",
+		TokenURL:        "/__hardwire/csrf",
+		TokenCookieName: "__hw_csrf_token",
+	},
+	Auth: &AuthConfig{},
+	RateLimit: &RateLimitConfig{
+		Requests:      0,
+		Window:        time.Minute,
+		MaxBodySize:   10 << 20,
+		MaxConcurrent: 0,
+	},
+	Idempotency: &IdempotencyConfig{
+		TTL:             10 * time.Minute,
+		HeaderName:      "Idempotency-Key",
+		FieldName:       "_idempotency_key",
+		DisableFormKeys: false,
+	},
 	Caching: &CachingConfig{
 		StaticRoutes: &CachingPolicy{
 			MaxAge: int(time.Hour.Seconds()),
@@ -112,9 +359,130 @@ func Configure(newConfig *Configuration) {
 	if newConfig.NoBuild {
 		Current.NoBuild = true
 	}
+	if newConfig.Prebuilt {
+		Current.Prebuilt = true
+	}
 	if newConfig.CleanBuild {
 		Current.CleanBuild = true
 	}
+	if newConfig.Builder != nil {
+		if newConfig.Builder.Runtime != "" {
+			Current.Builder.Runtime = newConfig.Builder.Runtime
+		}
+		if newConfig.Builder.GeneratorVersion != "" {
+			Current.Builder.GeneratorVersion = newConfig.Builder.GeneratorVersion
+		}
+		Current.Builder.FrozenLockfile = newConfig.Builder.FrozenLockfile
+		Current.Builder.Offline = newConfig.Builder.Offline
+	}
+	if newConfig.Realtime != nil {
+		if newConfig.Realtime.SubscribeURL != "" {
+			Current.Realtime.SubscribeURL = newConfig.Realtime.SubscribeURL
+		}
+		if newConfig.Realtime.HeartbeatInterval != 0 {
+			Current.Realtime.HeartbeatInterval = newConfig.Realtime.HeartbeatInterval
+		}
+		if newConfig.Realtime.ReconnectDelay != 0 {
+			Current.Realtime.ReconnectDelay = newConfig.Realtime.ReconnectDelay
+		}
+		if newConfig.Realtime.ReplayBufferSize != 0 {
+			Current.Realtime.ReplayBufferSize = newConfig.Realtime.ReplayBufferSize
+		}
+		if newConfig.Realtime.ReplayTTL != 0 {
+			Current.Realtime.ReplayTTL = newConfig.Realtime.ReplayTTL
+		}
+		if newConfig.Realtime.WebSocket {
+			Current.Realtime.WebSocket = true
+		}
+		if newConfig.Realtime.WebSocketURL != "" {
+			Current.Realtime.WebSocketURL = newConfig.Realtime.WebSocketURL
+		}
+		if newConfig.Realtime.WebSocketQueueSize != 0 {
+			Current.Realtime.WebSocketQueueSize = newConfig.Realtime.WebSocketQueueSize
+		}
+	}
+	if newConfig.Pagination != nil {
+		if newConfig.Pagination.DefaultLimit != 0 {
+			Current.Pagination.DefaultLimit = newConfig.Pagination.DefaultLimit
+		}
+		if newConfig.Pagination.MaxLimit != 0 {
+			Current.Pagination.MaxLimit = newConfig.Pagination.MaxLimit
+		}
+		if newConfig.Pagination.Trigger != "" {
+			Current.Pagination.Trigger = newConfig.Pagination.Trigger
+		}
+		if newConfig.Pagination.LoadMoreLabel != "" {
+			Current.Pagination.LoadMoreLabel = newConfig.Pagination.LoadMoreLabel
+		}
+	}
+	if newConfig.CSRF != nil {
+		if newConfig.CSRF.Disabled {
+			Current.CSRF.Disabled = true
+		}
+		if len(newConfig.CSRF.Secret) > 0 {
+			Current.CSRF.Secret = newConfig.CSRF.Secret
+		}
+		if newConfig.CSRF.TokenTTL != 0 {
+			Current.CSRF.TokenTTL = newConfig.CSRF.TokenTTL
+		}
+		if newConfig.CSRF.CookieName != "" {
+			Current.CSRF.CookieName = newConfig.CSRF.CookieName
+		}
+		if newConfig.CSRF.HeaderName != "" {
+			Current.CSRF.HeaderName = newConfig.CSRF.HeaderName
+		}
+		if newConfig.CSRF.FieldName != "" {
+			Current.CSRF.FieldName = newConfig.CSRF.FieldName
+		}
+		if newConfig.CSRF.TokenURL != "" {
+			Current.CSRF.TokenURL = newConfig.CSRF.TokenURL
+		}
+		if newConfig.CSRF.TokenCookieName != "" {
+			Current.CSRF.TokenCookieName = newConfig.CSRF.TokenCookieName
+		}
+	}
+	if newConfig.Auth != nil {
+		if newConfig.Auth.Identity != nil {
+			Current.Auth.Identity = newConfig.Auth.Identity
+		}
+		if newConfig.Auth.UnauthorizedRedirect != "" {
+			Current.Auth.UnauthorizedRedirect = newConfig.Auth.UnauthorizedRedirect
+		}
+		if newConfig.Auth.ForbiddenRedirect != "" {
+			Current.Auth.ForbiddenRedirect = newConfig.Auth.ForbiddenRedirect
+		}
+	}
+	if newConfig.RateLimit != nil {
+		if newConfig.RateLimit.Requests != 0 {
+			Current.RateLimit.Requests = newConfig.RateLimit.Requests
+		}
+		if newConfig.RateLimit.Window != 0 {
+			Current.RateLimit.Window = newConfig.RateLimit.Window
+		}
+		if newConfig.RateLimit.MaxBodySize != 0 {
+			Current.RateLimit.MaxBodySize = newConfig.RateLimit.MaxBodySize
+		}
+		if newConfig.RateLimit.MaxConcurrent != 0 {
+			Current.RateLimit.MaxConcurrent = newConfig.RateLimit.MaxConcurrent
+		}
+		if newConfig.RateLimit.ClientKey != nil {
+			Current.RateLimit.ClientKey = newConfig.RateLimit.ClientKey
+		}
+	}
+	if newConfig.Idempotency != nil {
+		if newConfig.Idempotency.TTL != 0 {
+			Current.Idempotency.TTL = newConfig.Idempotency.TTL
+		}
+		if newConfig.Idempotency.HeaderName != "" {
+			Current.Idempotency.HeaderName = newConfig.Idempotency.HeaderName
+		}
+		if newConfig.Idempotency.FieldName != "" {
+			Current.Idempotency.FieldName = newConfig.Idempotency.FieldName
+		}
+		if newConfig.Idempotency.DisableFormKeys {
+			Current.Idempotency.DisableFormKeys = true
+		}
+	}
 	if newConfig.Caching != nil {
 		if newConfig.Caching.StaticRoutes != nil {
 			Current.Caching.StaticRoutes = newConfig.Caching.StaticRoutes
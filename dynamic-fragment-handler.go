@@ -2,6 +2,7 @@ package hardwire
 
 import (
 	"net/http"
+	"net/url"
 
 	echo "github.com/labstack/echo/v4"
 	config "github.com/ncpa0/hardwire/configuration"
@@ -26,7 +27,16 @@ func createDynamicFragmentHandler(view *views.DynamicFragmentView, conf *config.
 			return nil
 		}
 
+		loadMore := c.QueryParam("cursor") != ""
+		hxCurrentUrl := c.Request().Header.Get("Hx-Current-Url")
+
 		routePathname := c.Request().Header.Get("Hardwire-Dynamic-Fragment-Request")
+		if routePathname == "" && loadMore {
+			// load more elements are rendered within the fragment, so they
+			// don't inherit the route header, the route is resolved from
+			// the page URL instead
+			routePathname = pageRoute(hxCurrentUrl)
+		}
 		if routePathname == "" {
 			err := c.String(http.StatusBadRequest, "Bad Request")
 			if err != nil {
@@ -38,7 +48,31 @@ func createDynamicFragmentHandler(view *views.DynamicFragmentView, conf *config.
 			return nil
 		}
 
-		hxCurrentUrl := c.Request().Header.Get("Hx-Current-Url")
+		// the route comes from the client, the guards of the page the
+		// fragment really is on could be skipped by claiming any other
+		if !views.RouteContainsFragment(routePathname, view) {
+			err := utils.RenderError(c, http.StatusForbidden, "Forbidden")
+			if err != nil {
+				return err
+			}
+			if conf.BeforeResponse != nil {
+				return conf.BeforeResponse(c)
+			}
+			return nil
+		}
+
+		err := resources.CheckRouteGuards(c, routePathname)
+		if err != nil {
+			err := utils.HandleError(c, err)
+			if err != nil {
+				return err
+			}
+			if conf.BeforeResponse != nil {
+				return conf.BeforeResponse(c)
+			}
+			return nil
+		}
+
 		params := utils.ParseUrlParams(routePathname, hxCurrentUrl)
 
 		handler, err := HardwireContext.GetResourceHandler(c, resKey)
@@ -69,7 +103,12 @@ func createDynamicFragmentHandler(view *views.DynamicFragmentView, conf *config.
 			return nil
 		}
 
-		html, err := view.Build(resource)
+		var html string
+		if page, isPage := resource.(resources.Paginated); isPage {
+			html, err = buildPage(view, page, c.QueryParams(), loadMore)
+		} else {
+			html, err = view.Build(resource)
+		}
 		if err != nil {
 			err := utils.HandleError(c, err)
 			if err != nil {
@@ -118,3 +157,17 @@ func createDynamicFragmentHandler(view *views.DynamicFragmentView, conf *config.
 		return nil
 	}
 }
+
+// Returns the route pathname of the page under the given URL, empty
+// if there's no such page
+func pageRoute(pageUrl string) string {
+	currentUrl, err := url.Parse(pageUrl)
+	if err != nil {
+		return ""
+	}
+	view := views.GetPageViewRegistry().GetView(currentUrl.Path)
+	if view.IsNil() {
+		return ""
+	}
+	return view.Get().GetRoutePathname()
+}
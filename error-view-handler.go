@@ -0,0 +1,125 @@
+package hardwire
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/utils"
+	"github.com/ncpa0/hardwire/views"
+)
+
+// Returns the contents of the page's body, the whole html if it has none
+func bodyContent(html string) string {
+	bodyIdx := strings.Index(html, "<body")
+	if bodyIdx == -1 {
+		return html
+	}
+	contentStart := strings.Index(html[bodyIdx:], ">")
+	contentEnd := strings.LastIndex(html, "</body>")
+	if contentStart == -1 || contentEnd == -1 {
+		return html
+	}
+	contentStart += bodyIdx + 1
+	if contentEnd < contentStart {
+		return html
+	}
+	return html[contentStart:contentEnd]
+}
+
+// Renders the errors returned by any handler or middleware (e.g. the
+// echo HTTPErrors of the CSRF checks and rate limits, and unknown routes)
+// with the error views. Errors without a matching error view, or for which
+// rendering fails, are handed over to the error handler the server had
+// before.
+func createHTTPErrorHandler(server *echo.Echo) echo.HTTPErrorHandler {
+	fallback := server.HTTPErrorHandler
+	if fallback == nil {
+		fallback = server.DefaultHTTPErrorHandler
+	}
+
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		code := http.StatusInternalServerError
+		message := http.StatusText(code)
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			code = httpErr.Code
+			message = fmt.Sprint(httpErr.Message)
+		}
+
+		// action errors are always reported with the `hardwire:error` event
+		if _, found := views.GetErrorView(code); !found && !isActionRequest(c) {
+			fallback(err, c)
+			return
+		}
+
+		if httpErr == nil {
+			c.Logger().Error(err)
+		}
+		if renderErr := renderErrorView(c, code, message); renderErr != nil {
+			fallback(err, c)
+		}
+	}
+}
+
+func isActionRequest(c echo.Context) bool {
+	return strings.HasPrefix(c.Request().URL.Path, "/__resources/")
+}
+
+// Responds with the error view matching the status code:
+//   - action requests trigger the `hardwire:error` event on the client,
+//     which can be used to show a toast, nothing gets swapped
+//   - boosted and other htmx page requests get the error page swapped
+//     into the body
+//   - fragment requests get the contents of the error page swapped in
+//     place of the fragment
+//   - everything else gets the whole error page
+//
+// htmx doesn't swap error responses, so the swapped errors are sent with
+// a 200 status, and the actual status in the `Hardwire-Error-Status` header.
+func renderErrorView(c echo.Context, code int, message string) error {
+	req := c.Request()
+	isHtmx := req.Header.Get("Hx-Request") != ""
+
+	if isActionRequest(c) {
+		utils.Htmx(c).Trigger("hardwire:error", map[string]interface{}{
+			"code":    code,
+			"message": message,
+		})
+		return c.String(code, message)
+	}
+
+	view, found := views.GetErrorView(code)
+	if !found {
+		return c.String(code, message)
+	}
+	html, err := view.RenderError(code, message)
+	if err != nil {
+		c.Logger().Error("error rendering the error view: ", err)
+		return c.String(code, message)
+	}
+
+	if !isHtmx {
+		html = injectCSRFScript(html)
+		return c.HTML(code, "<!DOCTYPE html>\n"+html)
+	}
+
+	c.Response().Header().Set("Hardwire-Error-Status", strconv.Itoa(code))
+	boosted := req.Header.Get("Hx-Boosted") == "true"
+	isFragment := req.Header.Get("Hardwire-Dynamic-Fragment-Request") != ""
+
+	if isFragment && !boosted {
+		return c.HTML(http.StatusOK, bodyContent(html))
+	}
+
+	utils.Htmx(c).Retarget("body")
+	utils.Htmx(c).Reswap("innerHTML")
+	return c.HTML(http.StatusOK, bodyContent(html))
+}
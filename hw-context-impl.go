@@ -3,11 +3,13 @@ package hardwire
 import (
 	"errors"
 	"fmt"
+	"net/url"
 
 	echo "github.com/labstack/echo/v4"
 	hw "github.com/ncpa0/hardwire/hw-context"
 	resources "github.com/ncpa0/hardwire/resources"
 	"github.com/ncpa0/hardwire/utils"
+	"github.com/ncpa0/hardwire/views"
 	. "github.com/ncpa0cpl/ezs"
 )
 
@@ -22,6 +24,10 @@ func (ctx *HwContext) GetResourceHandler(e echo.Context, resourceKey string) (fu
 	handler := resources.GetResourceHandler(entry)
 
 	return func(rootPath string, params map[string]string) (interface{}, error) {
+		err := resources.CheckResourceGuards(e, rootPath, entry)
+		if err != nil {
+			return nil, err
+		}
 		dynReqCtx := resources.NewDynamicRequestContext(e, params, rootPath)
 		return handler(dynReqCtx)
 	}, nil
@@ -48,14 +54,22 @@ func (ctx *HwContext) GetResource(ectx echo.Context, resourceKey string) (interf
 	return resource, nil
 }
 
-func (ctx *HwContext) BuildFragment(fragment hw.BuildableFragment, resources *Map[string, interface{}]) (string, error) {
+func (ctx *HwContext) BuildFragment(fragment hw.BuildableFragment, readyResources *Map[string, interface{}]) (string, error) {
 	resKey := fragment.ResourceKeys()[0]
 
-	resource, ok := resources.Get(resKey)
+	resource, ok := readyResources.Get(resKey)
 	if !ok || resource == nil {
 		return "", errors.New("resource not found")
 	}
 
+	if page, isPage := resource.(resources.Paginated); isPage {
+		view, isView := fragment.(*views.DynamicFragmentView)
+		if isView {
+			return buildPage(view, page, nil, false)
+		}
+		return fragment.Build(page.GetItems())
+	}
+
 	html, err := fragment.Build(resource)
 	if err != nil {
 		return "", err
@@ -63,3 +77,21 @@ func (ctx *HwContext) BuildFragment(fragment hw.BuildableFragment, resources *Ma
 
 	return html, nil
 }
+
+// Renders the items of the page, followed by the element loading the next
+// page. For load more requests only the swaps appending the items to the
+// list are returned.
+func buildPage(view *views.DynamicFragmentView, page resources.Paginated, query url.Values, loadMore bool) (string, error) {
+	html, err := view.Build(page.GetItems())
+	if err != nil {
+		return "", err
+	}
+
+	if loadMore {
+		return views.BuildLoadMoreSwap(view, html, query, page.GetNextCursor())
+	}
+	if page.GetNextCursor() == "" {
+		return html, nil
+	}
+	return views.InjectLoadMore(view, html, query, page.GetNextCursor())
+}
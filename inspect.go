@@ -0,0 +1,161 @@
+package hardwire
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	config "github.com/ncpa0/hardwire/configuration"
+	resources "github.com/ncpa0/hardwire/resources"
+	servestatic "github.com/ncpa0/hardwire/serve-static"
+	"github.com/ncpa0/hardwire/views"
+)
+
+type RouteKind string
+
+const (
+	RoutePage     RouteKind = "page"
+	RouteFragment RouteKind = "fragment"
+	RouteAction   RouteKind = "action"
+	RouteStatic   RouteKind = "static"
+	RouteStream   RouteKind = "stream"
+	RouteCSRF     RouteKind = "csrf"
+)
+
+// Describes a single route served by Hardwire
+type Route struct {
+	Kind      RouteKind
+	Method    string
+	Path      string
+	Resources []string
+	// Value of the Cache-Control header sent with the responses
+	Caching string
+}
+
+// Returns all the routes that will be added to the server. Views must be
+// loaded beforehand.
+func Routes() []Route {
+	routes := []Route{}
+
+	views.GetPageViewRegistry().ForEach(func(view *views.PageView) error {
+		if view.IsErrorView() {
+			return nil
+		}
+		caching := config.GenerateCacheHeaderForStaticRoute()
+		if view.IsDynamic() {
+			caching = config.GenerateCacheHeaderForDynamicRoute()
+		}
+		routes = append(routes, Route{
+			Kind:      RoutePage,
+			Method:    http.MethodGet,
+			Path:      view.GetRoutePathname(),
+			Resources: view.GetResourceKeys().ToSlice(),
+			Caching:   caching,
+		})
+		return nil
+	})
+
+	views.GetDynamicFragmentViewRegistry().ForEach(func(view *views.DynamicFragmentView) error {
+		routes = append(routes, Route{
+			Kind:      RouteFragment,
+			Method:    http.MethodGet,
+			Path:      view.GetRoutePathname(),
+			Resources: view.ResourceKeys(),
+			Caching:   config.GenerateCacheHeaderForFragments(),
+		})
+		return nil
+	})
+
+	for _, endpoint := range resources.ActionEndpoints() {
+		routes = append(routes, Route{
+			Kind:      RouteAction,
+			Method:    endpoint.Action.Method,
+			Path:      endpoint.Path,
+			Resources: []string{endpoint.Resource},
+			Caching:   "",
+		})
+	}
+
+	routes = append(routes, Route{
+		Kind:    RouteStream,
+		Method:  http.MethodGet,
+		Path:    config.Current.Realtime.SubscribeURL,
+		Caching: "no-store",
+	})
+
+	if config.Current.Realtime.WebSocket {
+		routes = append(routes, Route{
+			Kind:   RouteStream,
+			Method: http.MethodGet,
+			Path:   config.Current.Realtime.WebSocketURL,
+		})
+	}
+
+	if !config.Current.CSRF.Disabled {
+		routes = append(routes, Route{
+			Kind:    RouteCSRF,
+			Method:  http.MethodGet,
+			Path:    config.Current.CSRF.TokenURL,
+			Caching: "no-store",
+		})
+	}
+
+	routes = append(routes, Route{
+		Kind:    RouteStatic,
+		Method:  http.MethodGet,
+		Path:    config.Current.StaticURL + "/*",
+		Caching: servestatic.DefaultCacheControl(),
+	})
+
+	return routes
+}
+
+// Validates the loaded views metadata against the registered resources
+// and actions. Returns all the problems found, joined into a single error.
+func Check() error {
+	errs := []error{}
+
+	views.GetPageViewRegistry().ForEach(func(view *views.PageView) error {
+		if view.IsDynamic() {
+			err := validateResourcesAvailable(view.GetResourceKeys().ToSlice())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("page %s: %w", view.GetRoutePathname(), err))
+			}
+		}
+		return nil
+	})
+
+	views.GetDynamicFragmentViewRegistry().ForEach(func(view *views.DynamicFragmentView) error {
+		err := validateResourcesAvailable(view.ResourceKeys())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fragment %s: %w", view.GetRoutePathname(), err))
+		}
+		return nil
+	})
+
+	fragments := views.GetDynamicFragmentViewRegistry()
+	for island := range views.GetIslands().Iter() {
+		if fragments.GetFragmentById(island.FragmentID).IsNil() {
+			errs = append(errs, fmt.Errorf(
+				"island %s: fragment '%s' doesn't exist", island.ID, island.FragmentID,
+			))
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	err = resources.CheckActionEndpoints(wd)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	err = resources.CheckResourceDependencies()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -10,6 +10,8 @@ import (
 	hw "github.com/ncpa0/hardwire/hw-context"
 	resources "github.com/ncpa0/hardwire/resources"
 	servestatic "github.com/ncpa0/hardwire/serve-static"
+	templatebuilder "github.com/ncpa0/hardwire/template-builder"
+	"github.com/ncpa0/hardwire/utils"
 	"github.com/ncpa0/hardwire/views"
 )
 
@@ -20,9 +22,54 @@ type ActionContext = resources.ActionContext
 type Configuration = config.Configuration
 type CachingConfig = config.CachingConfig
 type CachingPolicy = config.CachingPolicy
+type PaginationConfig = config.PaginationConfig
+type CSRFConfig = config.CSRFConfig
+type Action = resources.Action
+type AuthConfig = config.AuthConfig
+type Guard = resources.Guard
+type GuardContext = resources.GuardContext
+type RateLimitConfig = config.RateLimitConfig
+type RateLimitStore = resources.RateLimitStore
+type IdempotencyConfig = config.IdempotencyConfig
+type ResourceFunc = resources.ResourceFunc
+type ResourceMiddleware = resources.ResourceMiddleware
+type Paginated = resources.Paginated
+type FieldErrors = utils.FieldErrors
+type HtmxHeaders = utils.HtmxHeaders
+type HtmxLocation = utils.HtmxLocation
+type IslandKind = views.IslandKind
+type IslandUpdate = views.IslandUpdate
 
 var ResourceReg = resources.ResourceReg
 var Configure = config.Configure
+var RegisterIslandKind = views.RegisterIslandKind
+
+// Re-renders the islands depending on any of the given resources, and
+// pushes them to all the pages subscribed to those islands.
+var Publish = resources.Publish
+
+type PubSub = resources.PubSub
+
+// Issues a CSRF token for the request, for forms and clients that
+// aren't rendered by hardwire.
+var CSRFToken = resources.CSRFToken
+
+var UseResourceMiddleware = resources.UseResourceMiddleware
+
+// Replaces the in-memory store tracking the action requests of the
+// clients, e.g. with one shared by all the server instances.
+var UseRateLimitStore = resources.UseRateLimitStore
+
+var GuardRoute = resources.GuardRoute
+var GetIdentity = resources.GetIdentity
+var Unauthorized = resources.Unauthorized
+var Forbidden = resources.Forbidden
+var RedirectTo = resources.RedirectTo
+
+// Replaces the in-memory PubSub used to broadcast resource changes,
+// e.g. with one that reaches all the server instances.
+var UsePubSub = resources.UsePubSub
+
 var HardwireContext hw.HardwireContext = &HwContext{}
 
 func redirectHandler(to string) echo.HandlerFunc {
@@ -43,6 +90,27 @@ func validateResourcesAvailable(resource []string) error {
 	return nil
 }
 
+// Builds the HTML and templates for all pages and writes the artifact bundle
+// to the configured html directory, without starting the server. The bundle
+// can then be served with the `Prebuilt` option enabled.
+func Build() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := templatebuilder.BuildArtifacts(wd)
+	if err != nil {
+		return err
+	}
+
+	if config.Current.DebugMode {
+		fmt.Printf("Build finished, %d artifacts written\n", len(manifest.Files))
+	}
+
+	return nil
+}
+
 // Builds the HTML and templates for all pages and adds the routes to the server
 func UseWith(server *echo.Echo) error {
 	pageViewRegistry := views.GetPageViewRegistry()
@@ -58,17 +126,16 @@ func UseWith(server *echo.Echo) error {
 		return err
 	}
 
-	resources.ValidateActionEndpoints()
+	err = Check()
+	if err != nil {
+		return err
+	}
 
 	err = pageViewRegistry.ForEach(func(view *views.PageView) error {
-		fmt.Printf("Adding new route: %s\n", view.GetRoutePathname())
-
-		if view.IsDynamic() {
-			err := validateResourcesAvailable(view.GetResourceKeys().ToSlice())
-			if err != nil {
-				return err
-			}
+		if view.IsErrorView() {
+			return nil
 		}
+		fmt.Printf("Adding new route: %s\n", view.GetRoutePathname())
 
 		pathname := view.GetRoutePathname()
 		server.GET(pathname, createPageViewHandler(view, config.Current))
@@ -86,12 +153,6 @@ func UseWith(server *echo.Echo) error {
 			fmt.Printf("Adding new dynamic fragment under route: %s\n", view.GetRoutePathname())
 		}
 
-		resKey := view.ResourceKeys()[0]
-		err := validateResourcesAvailable([]string{resKey})
-		if err != nil {
-			return err
-		}
-
 		pathname := view.GetRoutePathname()
 		server.GET(pathname, createDynamicFragmentHandler(view, config.Current))
 		server.GET(pathname+"/", redirectHandler(pathname))
@@ -103,7 +164,16 @@ func UseWith(server *echo.Echo) error {
 		return err
 	}
 
+	utils.UseErrorRenderer(renderErrorView)
+	server.HTTPErrorHandler = createHTTPErrorHandler(server)
+
 	resources.MountActionEndpoints(HardwireContext, server)
+	err = resources.MountSubscriptionEndpoint(HardwireContext, server)
+	if err != nil {
+		return err
+	}
+	resources.MountWebSocketEndpoint(HardwireContext, server)
+	resources.MountCSRFEndpoint(server)
 
 	if config.Current.DebugMode {
 		fmt.Printf(
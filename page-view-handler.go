@@ -1,11 +1,14 @@
 package hardwire
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	echo "github.com/labstack/echo/v4"
 	config "github.com/ncpa0/hardwire/configuration"
 	hw "github.com/ncpa0/hardwire/hw-context"
+	resources "github.com/ncpa0/hardwire/resources"
 	"github.com/ncpa0/hardwire/utils"
 	"github.com/ncpa0/hardwire/views"
 )
@@ -31,7 +34,8 @@ func createResponse(c echo.Context, view View) error {
 		c.Response().Header().Set("ETag", renderResult.Etag)
 	}
 
-	respHtml := renderResult.Html
+	respHtml := injectCSRFScript(renderResult.Html)
+	respHtml = injectIdempotencyScript(respHtml)
 	if boosted && renderResult.Head != "" {
 		respHtml = renderResult.Head + "\n\n" + respHtml
 	}
@@ -39,6 +43,74 @@ func createResponse(c echo.Context, view View) error {
 	return c.HTML(http.StatusOK, "<!DOCTYPE html>\n"+respHtml)
 }
 
+// Keeps the current CSRF token in a cookie, fetching a new one when it's
+// missing or expired, and sends it along with all the htmx requests and
+// posted forms. Requests made while the token is being fetched wait for it.
+const csrfScript = `<script>(function(){if(window.__hwCsrf)return;window.__hwCsrf=1;var C=%q,U=%q,H=%q,F=%q,p=null;` +
+	`function read(){var m=document.cookie.match(new RegExp("(?:^|; )"+C+"=([^;]*)"));return m?decodeURIComponent(m[1]):""}` +
+	`function get(){if(read())return Promise.resolve(read());if(!p)p=fetch(U,{credentials:"same-origin",cache:"no-store"})` +
+	`.then(function(){p=null;return read()},function(){p=null;return ""});return p}` +
+	`function field(f,t){var i=f.querySelector("input[name='"+F+"']");if(!i){i=document.createElement("input");` +
+	`i.type="hidden";i.name=F;f.appendChild(i)}i.value=t}` +
+	`get();` +
+	`document.addEventListener("htmx:confirm",function(e){if(read())return;e.preventDefault();get().then(function(){e.detail.issueRequest(true)})});` +
+	`document.addEventListener("htmx:configRequest",function(e){var t=read();if(t)e.detail.headers[H]=t});` +
+	`document.addEventListener("submit",function(e){var f=e.target;if(e.defaultPrevented||(f.method||"").toLowerCase()!=="post")return;` +
+	`var t=read();if(t){field(f,t);return}e.preventDefault();get().then(function(t){field(f,t);f.submit()})});` +
+	`})();</script>`
+
+// Adds the script handling the CSRF tokens to the page. The page itself
+// holds no token, so it can be cached and shared like any other page.
+func injectCSRFScript(html string) string {
+	conf := config.Current.CSRF
+	if conf.Disabled {
+		return html
+	}
+
+	script := fmt.Sprintf(csrfScript, conf.TokenCookieName, conf.TokenURL, conf.HeaderName, conf.FieldName)
+	if idx := strings.Index(html, "</head>"); idx != -1 {
+		return html[:idx] + script + html[idx:]
+	}
+	if idx := strings.LastIndex(html, "</body>"); idx != -1 {
+		return html[:idx] + script + html[idx:]
+	}
+	// partial responses are swapped into a page that already has it
+	return html
+}
+
+// Sends an idempotency key with every form submitted to an action
+// endpoint. The key is generated on the client, and regenerated once the
+// request succeeds, so that the next submission is not taken for a
+// duplicate of the previous one. A resubmitted form sends the same key.
+const idempotencyKeyScript = `<script>(function(){if(window.__hwIdempotency)return;window.__hwIdempotency=1;var H=%q,F=%q;` +
+	`function key(f){if(!f.__hwKey)f.__hwKey=crypto.randomUUID();return f.__hwKey}` +
+	`function form(e){return e.detail.elt.closest&&e.detail.elt.closest("form")}` +
+	`function isAction(p){try{return new URL(p,location.href).pathname.indexOf("/__resources/")===0}catch(_){return false}}` +
+	`document.addEventListener("htmx:configRequest",function(e){var f=form(e);` +
+	`if(f&&e.detail.verb!=="get"&&isAction(e.detail.path))e.detail.headers[H]=key(f)});` +
+	`document.addEventListener("htmx:afterRequest",function(e){var f=form(e);if(f&&e.detail.successful)delete f.__hwKey});` +
+	`document.addEventListener("submit",function(e){var f=e.target;if(e.defaultPrevented||!isAction(f.action))return;` +
+	`var i=f.querySelector("input[name='"+F+"']");if(!i){i=document.createElement("input");i.type="hidden";i.name=F;f.appendChild(i)}` +
+	`i.value=key(f)});` +
+	`})();</script>`
+
+// Adds the script handling the idempotency keys of the forms to the
+// page. The keys are not part of the html, so a page restored from the
+// cache doesn't resend the keys of earlier submissions.
+func injectIdempotencyScript(html string) string {
+	conf := config.Current.Idempotency
+	if conf.DisableFormKeys {
+		return html
+	}
+
+	script := fmt.Sprintf(idempotencyKeyScript, conf.HeaderName, conf.FieldName)
+	if idx := strings.LastIndex(html, "</body>"); idx != -1 {
+		return html[:idx] + script + html[idx:]
+	}
+	// partial responses are swapped into a page that already has it
+	return html
+}
+
 func createPageViewHandler(view *views.PageView, conf *config.Configuration) func(c echo.Context) error {
 	if view.Metadata.ShouldRedirect {
 		return func(c echo.Context) error {
@@ -54,6 +126,18 @@ func createPageViewHandler(view *views.PageView, conf *config.Configuration) fun
 	}
 
 	return func(c echo.Context) error {
+		err := resources.CheckRouteGuards(c, view.GetRoutePathname())
+		if err != nil {
+			err := utils.HandleError(c, err)
+			if err != nil {
+				return err
+			}
+			if conf.BeforeResponse != nil {
+				return conf.BeforeResponse(c)
+			}
+			return nil
+		}
+
 		selector := c.Request().Header.Get("HX-Target")
 
 		c.Response().Header().Set("Vary", "HX-Target")
@@ -84,7 +168,7 @@ func createPageViewHandler(view *views.PageView, conf *config.Configuration) fun
 			}
 		}
 
-		err := createResponse(c, view)
+		err = createResponse(c, view)
 		if err != nil {
 			return err
 		}
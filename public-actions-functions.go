@@ -5,33 +5,55 @@ import (
 )
 
 func RegisterPostAction[T interface{}](
-	resource *resources.ResourceEntry,
+	resource resources.ResourceEntryRef,
 	name string,
 	action func(body *T, ctx *resources.ActionContext) error,
-) {
-	resources.RegisterPostAction(resource, name, action)
+) *resources.Action {
+	return resources.RegisterPostAction(resource, name, action)
 }
 
 func RegisterPutAction[T interface{}](
-	resource *resources.ResourceEntry,
+	resource resources.ResourceEntryRef,
 	name string,
 	action func(body *T, ctx *resources.ActionContext) error,
-) {
-	resources.RegisterPutAction(resource, name, action)
+) *resources.Action {
+	return resources.RegisterPutAction(resource, name, action)
 }
 
 func RegisterPatchAction[T interface{}](
-	resource *resources.ResourceEntry,
+	resource resources.ResourceEntryRef,
 	name string,
 	action func(body *T, ctx *resources.ActionContext) error,
-) {
-	resources.RegisterPatchAction(resource, name, action)
+) *resources.Action {
+	return resources.RegisterPatchAction(resource, name, action)
 }
 
 func RegisterDeleteAction[T interface{}](
-	resource *resources.ResourceEntry,
+	resource resources.ResourceEntryRef,
 	name string,
 	action func(body *T, ctx *resources.ActionContext) error,
-) {
-	resources.RegisterDeleteAction(resource, name, action)
+) *resources.Action {
+	return resources.RegisterDeleteAction(resource, name, action)
+}
+
+// Registers the resource, keeping the type of the value it provides, e.g.
+// `hardwire.RegisterResource(hardwire.ResourceReg, "todos", todosResource)`
+func RegisterResource[T interface{}](
+	reg *resources.ResourceRegistry,
+	name string,
+	resource resources.Resource[T],
+) *resources.TypedResourceEntry[T] {
+	return resources.RegisterResource(reg, name, resource)
+}
+
+// Fetches the resource of the given name for the page the action was made
+// from, as a value of type T.
+func GetResource[T interface{}](actx *resources.ActionContext, name string) (T, error) {
+	return resources.GetActionResource[T](actx, name)
+}
+
+// Returns the resolved value of one of the dependencies of the resource
+// being fetched, as a value of type T.
+func GetDependency[T interface{}](ctx *resources.DynamicRequestContext, resourceName string) (T, error) {
+	return resources.GetDependency[T](ctx, resourceName)
 }
@@ -1,16 +1,20 @@
 package resourceprovider
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"slices"
+	"strings"
+	"sync"
 
+	"github.com/antchfx/xmlquery"
 	echo "github.com/labstack/echo/v4"
 	hw "github.com/ncpa0/hardwire/hw-context"
 	"github.com/ncpa0/hardwire/utils"
 	"github.com/ncpa0/hardwire/views"
-	"github.com/ncpa0cpl/ezs"
+	. "github.com/ncpa0cpl/ezs"
 )
 
 type ActionContext struct {
@@ -20,6 +24,24 @@ type ActionContext struct {
 	// list of islands that have been written
 	// to the response so far
 	updatedIslands []string
+	writer         *AtomicRespWriter
+	cancel         context.CancelCauseFunc
+}
+
+func (actx *ActionContext) respWriter() *AtomicRespWriter {
+	if actx.writer == nil {
+		actx.writer = &AtomicRespWriter{
+			actionCtx: actx,
+			mutex:     &sync.Mutex{},
+		}
+	}
+	return actx.writer
+}
+
+// Sets the htmx response headers, e.g. to trigger client events or to
+// change where the response gets swapped.
+func (actx *ActionContext) Htmx() *utils.HtmxHeaders {
+	return utils.Htmx(actx.Echo)
 }
 
 func (actx *ActionContext) Reload() {
@@ -45,7 +67,7 @@ func (actx *ActionContext) Reload() {
 		return
 	}
 
-	actx.Echo.Response().Header().Set("HX-Retarget", "body")
+	actx.Htmx().Retarget("body")
 	actx.Echo.HTML(200, renderResult.Html)
 }
 
@@ -70,12 +92,13 @@ func (actx *ActionContext) Redirect(to string) {
 		return
 	}
 
-	actx.Echo.Response().Header().Set("HX-Push-Url", to)
-	actx.Echo.Response().Header().Set("HX-Retarget", "body")
+	actx.Htmx().PushUrl(to)
+	actx.Htmx().Retarget("body")
 	actx.Echo.HTML(200, renderResult.Html)
 }
 
 func (actx *ActionContext) UpdateIslands(islandsIDs ...string) {
+	resetResolver(actx.Echo)
 	allIslands := views.GetIslands()
 	dynFragments := views.GetDynamicFragmentViewRegistry()
 	for _, islandID := range islandsIDs {
@@ -100,7 +123,7 @@ func (actx *ActionContext) UpdateIslands(islandsIDs ...string) {
 		}
 
 		requiredResources := fragment.Get().ResourceKeys()
-		resources := ezs.NewMap(map[string]interface{}{})
+		resources := NewMap(map[string]interface{}{})
 
 		for _, resourceKey := range requiredResources {
 			res, err := actx.HwContext.GetResource(actx.Echo, resourceKey)
@@ -126,10 +149,108 @@ func (actx *ActionContext) UpdateIslands(islandsIDs ...string) {
 			swap.Extension = "morph"
 		}
 
-		utils.SetChunkedEnc(actx.Echo)
-		actx.Echo.Response().Write([]byte(fmt.Sprintf("\n<div hx-swap-oob=\"%s\">%s</div>", swap.Build(), html)))
-		actx.Echo.Response().Flush()
-		actx.updatedIslands = append(actx.updatedIslands, islandID)
-		actx.wasResponseWritten = true
+		err = actx.respWriter().Write(islandID, []byte(fmt.Sprintf("\n<div hx-swap-oob=\"%s\">%s</div>", swap.Build(), html)))
+		if err != nil {
+			actx.Echo.Logger().Error("error writing island update: ", err)
+			return
+		}
+	}
+}
+
+// Re-renders the islands depending on any of the given resources for
+// every page currently subscribed to them, including the ones open by
+// other users. Each page is rendered with its own params and identity.
+func (actx *ActionContext) Broadcast(resourceKeys ...string) {
+	Publish(resourceKeys...)
+}
+
+// Renders the template with the given data and sends it to the client as
+// an out of band swap, in the same stream as the island updates.
+//
+// The source is either the route of a dynamic fragment (e.g. `/todos/list`),
+// rendered into every island displaying it, or the route of a page followed
+// by the selector of one of its elements (e.g. `/todos #new-todo`), which
+// replaces the element with the same id as the rendered one.
+func (actx *ActionContext) Render(source string, data interface{}) error {
+	route, selector, isElement := strings.Cut(strings.TrimSpace(source), " ")
+
+	if !isElement {
+		fragment := views.GetDynamicFragmentViewRegistry().GetFragment(route)
+		if fragment.IsNil() {
+			return fmt.Errorf("fragment not found: %s", route)
+		}
+		return actx.renderFragment(fragment.Get(), data)
+	}
+
+	view := views.GetPageViewRegistry().GetView(route)
+	if view.IsNil() {
+		return fmt.Errorf("page not found: %s", route)
+	}
+	node := view.Get().QuerySelector(strings.TrimSpace(selector))
+	if node.IsNil() {
+		return fmt.Errorf("element '%s' not found in page %s", selector, route)
+	}
+	html, err := node.Get().Build(data)
+	if err != nil {
+		return err
+	}
+	// the element is swapped by its id, without one htmx
+	// would have nothing to replace
+	root, err := xmlquery.Parse(strings.NewReader(html))
+	if err != nil {
+		return err
+	}
+	element := root.FirstChild
+	for element != nil && element.Type != xmlquery.ElementNode {
+		element = element.NextSibling
+	}
+	if element == nil || element.SelectAttr("id") == "" {
+		return fmt.Errorf("element '%s' of page %s has no id, it can't be swapped", selector, route)
+	}
+	return actx.Swap("", html, "true")
+}
+
+// Renders the fragment into the islands displaying it that the client
+// requested to be updated (in the `Hardwire-Islands-Update` header).
+func (actx *ActionContext) renderFragment(fragment *views.DynamicFragmentView, data interface{}) error {
+	resKeys := fragment.ResourceKeys()
+	if len(resKeys) != 1 {
+		return fmt.Errorf(
+			"fragment %s depends on %d resources, only fragments of a single resource can be rendered with data",
+			fragment.GetRoutePathname(), len(resKeys),
+		)
+	}
+	html, err := actx.HwContext.BuildFragment(fragment, NewMap(map[string]interface{}{resKeys[0]: data}))
+	if err != nil {
+		return err
+	}
+
+	islandIDs := utils.ParseHeaderList(
+		actx.Echo.Request().Header.Get("Hardwire-Islands-Update"),
+	)
+	morphSwap := actx.Echo.Request().Header.Get("Hardwire-Htmx-Morph") == "true"
+	islands := views.GetIslands().Filter(func(island *views.Island, _ int) bool {
+		return island.FragmentID == fragment.GetID() && Contains(islandIDs, island.ID)
+	})
+	for island := range islands.Iter() {
+		err := sendIslandUpdate(actx.Echo, actx.respWriter(), island, html, morphSwap, NewArray([]string{}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Swaps the html into the element matching the selector, using the given
+// swap mode (e.g. `innerHTML`, `outerHTML` or `beforeend`).
+func (actx *ActionContext) Swap(selector string, html string, mode string) error {
+	swap := utils.OobSwap{
+		Mode:     mode,
+		Selector: selector,
+	}
+	swapHtml, err := swap.Apply(html)
+	if err != nil {
+		return err
 	}
+	return actx.respWriter().Write("", []byte("\n"+swapHtml))
 }
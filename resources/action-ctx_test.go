@@ -0,0 +1,68 @@
+package resourceprovider_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire"
+	"github.com/ncpa0/hardwire/configuration"
+	resources "github.com/ncpa0/hardwire/resources"
+	"github.com/ncpa0/hardwire/views"
+	"github.com/stretchr/testify/assert"
+)
+
+type renderTestBody struct{}
+
+func TestRenderListIslandFragment(t *testing.T) {
+	ass := assert.New(t)
+	disableCSRF(t)
+
+	dir := t.TempDir()
+	write := func(name string, content string) {
+		err := os.MkdirAll(path.Dir(path.Join(dir, name)), 0755)
+		if err == nil {
+			err = os.WriteFile(path.Join(dir, name), []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("__dyn/render-list.template.html",
+		`<dynamic-fragment id="render-todos" class="dynamic-list island_render-todos">`+
+			`{{range .}}<div class="dynamic-list-element" data-item-key="{{.}}">{{.}}</div>{{end}}`+
+			`</dynamic-fragment>`)
+	write("__dyn/render-list.meta.json", `{"version":1,"resourceName":"render-todos","hash":"renderfrag"}`)
+	write("__islands/render-todos.meta.json",
+		`{"version":1,"id":"render-todos","fragmentID":"renderfrag","resource":"render-todos","type":"list"}`)
+
+	oldHtmlDir := configuration.Current.HtmlDir
+	configuration.Current.HtmlDir = dir
+	t.Cleanup(func() { configuration.Current.HtmlDir = oldHtmlDir })
+	if !ass.NoError(views.LoadBuiltViews(dir)) {
+		return
+	}
+
+	entry := resources.ResourceReg.Register("render-todos", &idempotencyTestResource{})
+	resources.RegisterPostAction(entry, "render", func(body *renderTestBody, actx *resources.ActionContext) error {
+		return actx.Render("/__dyn/render-list", []string{"a", "b"})
+	})
+
+	server := echo.New()
+	server.Logger.SetOutput(io.Discard)
+	resources.MountActionEndpoints(hardwire.HardwireContext, server)
+
+	// a first render, the client doesn't send the order of the list items
+	req := httptest.NewRequest(http.MethodPost, resources.ActionEndpointPath("render-todos", "render"), nil)
+	req.Header.Set("Hardwire-Islands-Update", "render-todos")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	ass.Equal(http.StatusOK, rec.Code)
+	ass.Contains(rec.Body.String(), `:#render-todos"`)
+	ass.Contains(rec.Body.String(), `data-item-key="b"`)
+}
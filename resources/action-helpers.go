@@ -1,44 +1,80 @@
 package resourceprovider
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
-	"reflect"
+	"slices"
+	"strings"
 
 	echo "github.com/labstack/echo/v4"
 	"github.com/ncpa0/hardwire/configuration"
+	"github.com/ncpa0/hardwire/utils"
+	"github.com/ncpa0/hardwire/views"
 )
 
-func bindFormParams(ctx echo.Context, bodyPtr interface{}) error {
-	go (func() {
-		if r := recover(); r != nil {
-			ctx.Logger().Error("internal error binding form params: ", r)
+// Binds the request data onto the action body. Form and multipart bodies
+// (and the query params of requests without a body) are bound with the
+// `form` tags, any other bodies with the echo binder. Form bodies are
+// bound along with the path and query params (e.g. of DELETE requests,
+// which htmx sends as query params). The body values take precedence
+// over the query params, the path params over both, as the route (and
+// its guards) resolved them from the URL.
+func bindActionBody(ctx echo.Context, body interface{}) error {
+	req := ctx.Request()
+	contentType := req.Header.Get(echo.HeaderContentType)
+
+	switch {
+	case strings.HasPrefix(contentType, echo.MIMEMultipartForm):
+		form, err := ctx.MultipartForm()
+		if err != nil {
+			return bindError(ctx, err)
 		}
-	})()
-
-	bodyValueElem := reflect.ValueOf(bodyPtr).Elem()
-	body := bodyValueElem.Interface()
-
-	if param, err := ctx.FormParams(); err == nil {
-		v := reflect.ValueOf(body)
-		// iterate over keys of the body struct
-		for i := 0; i < v.NumField(); i++ {
-			field := v.Field(i)
-			// only bind fields of type string
-			if field.Kind() == reflect.String {
-				fieldName := reflect.TypeOf(body).Field(i).Name
-				paramValue := param.Get(fieldName)
-				if paramValue != "" {
-					// assign the value to the
-					bodyValueElem.Field(i).SetString(paramValue)
-				}
-			}
+		return utils.BindForm(withRequestParams(ctx, form.Value), form.File, body)
+	case strings.HasPrefix(contentType, echo.MIMEApplicationForm):
+		params, err := ctx.FormParams()
+		if err != nil {
+			return bindError(ctx, err)
 		}
-		return nil
-	} else {
-		return err
+		return utils.BindForm(withRequestParams(ctx, params), nil, body)
+	case req.ContentLength == 0:
+		return utils.BindForm(withRequestParams(ctx, nil), nil, body)
+	}
+
+	err := ctx.Bind(body)
+	if err == nil {
+		// the echo binder lets the body override the path params
+		err = (&echo.DefaultBinder{}).BindPathParams(ctx, body)
 	}
+	if err != nil {
+		return bindError(ctx, err)
+	}
+	return nil
+}
+
+// Merges the query params, the values of the form body and the path
+// params, the later ones overriding the former.
+func withRequestParams(ctx echo.Context, formValues url.Values) url.Values {
+	values := url.Values{}
+	for key, vals := range ctx.QueryParams() {
+		values[key] = vals
+	}
+	for key, vals := range formValues {
+		values[key] = vals
+	}
+	for _, name := range ctx.ParamNames() {
+		values.Set(name, ctx.Param(name))
+	}
+	return values
+}
+
+func bindError(ctx echo.Context, err error) error {
+	if isBodyTooLarge(err) {
+		return payloadTooLarge(ctx)
+	}
+	return echo.ErrBadRequest
 }
 
 type ActionMetadata struct {
@@ -46,38 +82,78 @@ type ActionMetadata struct {
 	Action    string   `json:"action"`
 	Method    string   `json:"method"`
 	IslandIDs []string `json:"islandIDs"`
+	// Route of the page the action is used on
+	Route string `json:"route"`
 }
 
 type ActionsMetadata struct {
+	Version           int              `json:"version"`
 	RegisteredActions []ActionMetadata `json:"registeredActions"`
 }
 
-func ValidateActionEndpoints() {
-	outDir := configuration.Current.HtmlDir
-	actionsMetaFilepath := filepath.Join(outDir, "__actions.meta.json")
+func (meta *ActionsMetadata) GetVersion() int {
+	return meta.Version
+}
 
-	fileContent, err := os.ReadFile(actionsMetaFilepath)
-	if err != nil {
-		panic("Unable to read the actions metadata file")
+func (meta *ActionsMetadata) Validate() error {
+	for i, action := range meta.RegisteredActions {
+		field := fmt.Sprintf("registeredActions[%d]", i)
+		if action.Resource == "" {
+			return &views.MetadataError{Field: field + ".resource", Message: "must not be empty"}
+		}
+		if action.Action == "" {
+			return &views.MetadataError{Field: field + ".action", Message: "must not be empty"}
+		}
+		switch action.Method {
+		case "GET", "POST", "PUT", "PATCH", "DELETE":
+		default:
+			return &views.MetadataError{
+				Field:   field + ".method",
+				Message: fmt.Sprintf("must be one of GET, POST, PUT, PATCH or DELETE, got '%s'", action.Method),
+			}
+		}
 	}
+	return nil
+}
 
-	// unmarchal the json file
-	var actionsMeta ActionsMetadata
-	err = json.Unmarshal(fileContent, &actionsMeta)
+// Checks that every action referenced in the actions metadata file
+// has a matching resource and action registered. Returns all the
+// problems found, joined into a single error. The html directory is
+// resolved against the given working directory.
+func CheckActionEndpoints(wd string) error {
+	outDir := configuration.Current.HtmlDir
+	if !filepath.IsAbs(outDir) {
+		outDir = filepath.Join(wd, outDir)
+	}
+	actionsMetaFilepath := filepath.Join(outDir, "__actions.meta.json")
 
+	var actionsMeta ActionsMetadata
+	err := views.DecodeMetafile(actionsMetaFilepath, &actionsMeta)
 	if err != nil {
-		panic("Actions metadata file is corrupted")
+		if os.IsNotExist(err) {
+			return errors.New("Unable to read the actions metadata file")
+		}
+		return err
 	}
 
+	errs := []error{}
 	for _, actionMeta := range actionsMeta.RegisteredActions {
 		res, found := ResourceReg.find(actionMeta.Resource)
 		if !found {
-			panic("Resource referenced by one of the actions doesn't exist: " + actionMeta.Resource)
+			errs = append(errs, errors.New("Resource referenced by one of the actions doesn't exist: "+actionMeta.Resource))
+			continue
 		}
 
-		found, _ = res.findAction(actionMeta.Method, actionMeta.Action)
+		found, action := res.findAction(actionMeta.Method, actionMeta.Action)
 		if !found {
-			panic("Action used does not exist: " + actionMeta.Method + "/" + actionMeta.Action)
+			errs = append(errs, errors.New("Action used does not exist: "+actionMeta.Method+"/"+actionMeta.Action))
+			continue
+		}
+		// remembered for checking the route guards of the action
+		if actionMeta.Route != "" && !slices.Contains(action.routes, actionMeta.Route) {
+			action.routes = append(action.routes, actionMeta.Route)
 		}
 	}
+
+	return errors.Join(errs...)
 }
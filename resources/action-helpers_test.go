@@ -0,0 +1,111 @@
+package resourceprovider_test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/configuration"
+	resources "github.com/ncpa0/hardwire/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+type bindTestBody struct {
+	ID    int    `form:"id"`
+	Title string `form:"title"`
+}
+
+func TestActionBodyBindsQueryParams(t *testing.T) {
+	ass := assert.New(t)
+	disableCSRF(t)
+
+	var bound bindTestBody
+	handler := func(body *bindTestBody, ctx *resources.ActionContext) error {
+		bound = *body
+		return nil
+	}
+	entry := resources.ResourceReg.Register("bind-test", &idempotencyTestResource{})
+	resources.RegisterDeleteAction(entry, "remove", handler)
+	resources.RegisterPostAction(entry, "update", handler)
+	resources.RegisterPostAction(entry, "rename/:id", handler)
+
+	server := echo.New()
+	server.Logger.SetOutput(io.Discard)
+	resources.MountActionEndpoints(nil, server)
+
+	send := func(method string, name string, query string, form map[string]string) int {
+		payload := &bytes.Buffer{}
+		contentType := ""
+		if form != nil {
+			writer := multipart.NewWriter(payload)
+			for key, value := range form {
+				writer.WriteField(key, value)
+			}
+			writer.Close()
+			contentType = writer.FormDataContentType()
+		}
+		req := httptest.NewRequest(method, resources.ActionEndpointPath("bind-test", name)+query, payload)
+		req.Header.Set(echo.HeaderContentType, contentType)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	bound = bindTestBody{}
+	ass.Equal(http.StatusNoContent, send(http.MethodDelete, "remove", "?id=5", nil))
+	ass.Equal(bindTestBody{ID: 5}, bound)
+
+	// the body values take precedence over the query
+	bound = bindTestBody{}
+	ass.Equal(http.StatusNoContent, send(http.MethodPost, "update", "?id=7&title=old", map[string]string{"title": "new"}))
+	ass.Equal(bindTestBody{ID: 7, Title: "new"}, bound)
+
+	// the path params can't be overridden
+	bound = bindTestBody{}
+	ass.Equal(http.StatusNoContent, send(http.MethodPost, "rename/3", "?id=8", map[string]string{"id": "9", "title": "new"}))
+	ass.Equal(bindTestBody{ID: 3, Title: "new"}, bound)
+}
+
+// Disables the CSRF protection for the duration of the test
+func disableCSRF(t *testing.T) {
+	disabled := configuration.Current.CSRF.Disabled
+	configuration.Current.CSRF.Disabled = true
+	t.Cleanup(func() {
+		configuration.Current.CSRF.Disabled = disabled
+	})
+}
+
+func TestCheckActionEndpointsResolvesHtmlDir(t *testing.T) {
+	ass := assert.New(t)
+
+	wd := t.TempDir()
+	err := os.MkdirAll(path.Join(wd, "pages"), 0755)
+	if err == nil {
+		err = os.WriteFile(
+			path.Join(wd, "pages", "__actions.meta.json"),
+			[]byte(`{"version":1,"registeredActions":[{"resource":"check-endpoints","action":"run","method":"POST"}]}`),
+			0644,
+		)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	htmlDir := configuration.Current.HtmlDir
+	configuration.Current.HtmlDir = "pages"
+	t.Cleanup(func() { configuration.Current.HtmlDir = htmlDir })
+
+	entry := resources.ResourceReg.Register("check-endpoints", &idempotencyTestResource{})
+	resources.RegisterPostAction(entry, "run", func(body *idempotencyTestBody, ctx *resources.ActionContext) error {
+		return nil
+	})
+
+	ass.NoError(resources.CheckActionEndpoints(wd))
+	ass.Error(resources.CheckActionEndpoints(t.TempDir()))
+}
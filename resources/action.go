@@ -2,16 +2,22 @@ package resourceprovider
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/configuration"
 	hw "github.com/ncpa0/hardwire/hw-context"
 	"github.com/ncpa0/hardwire/utils"
 	"github.com/ncpa0/hardwire/views"
 	. "github.com/ncpa0cpl/ezs"
 )
 
+// Writes the swaps of an action to the response one at a time, in the
+// order they're finished. Swaps not updating an island are written with
+// an empty island ID.
 type AtomicRespWriter struct {
 	actionCtx *ActionContext
 	mutex     *sync.Mutex
@@ -20,15 +26,25 @@ type AtomicRespWriter struct {
 func (arw *AtomicRespWriter) Write(islandID string, data []byte) error {
 	arw.mutex.Lock()
 	defer arw.mutex.Unlock()
+	// the headers set by the resources fetched in parallel are either
+	// sent with the first chunk or ignored, never set halfway through
+	defer utils.LockResponseHeaders(arw.actionCtx.Echo)()
 
 	utils.SetChunkedEnc(arw.actionCtx.Echo)
 	resp := arw.actionCtx.Echo.Response()
 	_, err := resp.Write(data)
 	if err != nil {
+		// the client won't receive anything else, rendering the
+		// remaining islands can be stopped
+		if arw.actionCtx.cancel != nil {
+			arw.actionCtx.cancel(err)
+		}
 		return err
 	}
 	resp.Flush()
-	arw.actionCtx.updatedIslands = append(arw.actionCtx.updatedIslands, islandID)
+	if islandID != "" {
+		arw.actionCtx.updatedIslands = append(arw.actionCtx.updatedIslands, islandID)
+	}
 	arw.actionCtx.wasResponseWritten = true
 	return nil
 }
@@ -46,10 +62,26 @@ func (qi *QueuedIsland) CanRender(readyRes *Map[string, interface{}]) bool {
 }
 
 type Action struct {
-	Name    string
-	Method  string
-	NewBody func() interface{}
-	Handler func(body interface{}, ctx *ActionContext) error
+	Name   string
+	Method string
+	// Key of the resource the action is registered on
+	Resource string
+	NewBody  func() interface{}
+	Handler  func(body interface{}, ctx *ActionContext) error
+	// When set, requests to the action are not checked for a CSRF token
+	skipCSRF bool
+	guards   []Guard
+	limits   actionLimits
+	timeout  time.Duration
+	// routes of the pages the action is used on, from the actions metadata
+	routes []string
+}
+
+// Excludes the action from the CSRF protection, e.g. for actions called
+// by third-party services rather than the rendered pages.
+func (action *Action) SkipCSRF() *Action {
+	action.skipCSRF = true
+	return action
 }
 
 func NewAction[T interface{}](
@@ -72,23 +104,70 @@ func NewAction[T interface{}](
 }
 
 func (action *Action) Perform(hwContext hw.HardwireContext, ctx echo.Context) error {
-	body := action.NewBody()
-	err := ctx.Bind(body)
+	release, err := action.applyLimits(ctx)
 	if err != nil {
-		return echo.ErrBadRequest
+		return err
+	}
+	defer release()
+
+	if !action.skipCSRF && !configuration.Current.CSRF.Disabled {
+		err := verifyCSRF(ctx)
+		if err != nil {
+			return err
+		}
 	}
-	err = bindFormParams(ctx, body)
+
+	err = checkActionGuards(ctx, action)
 	if err != nil {
-		return echo.ErrInternalServerError
+		return utils.HandleError(ctx, err)
 	}
+
+	body := action.NewBody()
+	err = bindActionBody(ctx, body)
+	if err != nil {
+		var fieldErrs utils.FieldErrors
+		if errors.As(err, &fieldErrs) {
+			return sendFieldErrors(ctx, fieldErrs)
+		}
+		return err
+	}
+	if fieldErrs := utils.Validate(body); len(fieldErrs) > 0 {
+		return sendFieldErrors(ctx, fieldErrs)
+	}
+
+	if key := idempotencyKey(ctx); key != "" {
+		scope := fmt.Sprintf("%s/%s/%s:%s:%s", action.Resource, action.Method, action.Name, clientKey(ctx), key)
+		return performIdempotent(ctx, scope, func() error {
+			return action.run(hwContext, ctx, body)
+		})
+	}
+
+	return action.run(hwContext, ctx, body)
+}
+
+// Runs the action handler, then renders and streams the islands
+// requested to be updated.
+func (action *Action) run(hwContext hw.HardwireContext, ctx echo.Context, body interface{}) error {
 	actx := &ActionContext{
 		HwContext: hwContext,
 		Echo:      ctx,
 	}
-	err = action.Handler(body, actx)
+	done := action.withRequestContext(ctx, actx)
+	defer done()
+
+	// created before the handler runs, so that anything it renders
+	// is written in order with the island updates
+	atomicWriter := actx.respWriter()
+	err := action.Handler(body, actx)
+	if isActionTimeout(ctx, err) {
+		ctx.Logger().Errorf("action '%s' timed out: %s", action.Name, err.Error())
+		return echo.NewHTTPError(http.StatusGatewayTimeout, "Action timed out")
+	}
 	if err != nil {
 		return err
 	}
+	// the handler could have changed any of the resources
+	resetResolver(ctx)
 
 	islandIDs := utils.ParseHeaderList(
 		ctx.Request().Header.Get("Hardwire-Islands-Update"),
@@ -97,14 +176,16 @@ func (action *Action) Perform(hwContext hw.HardwireContext, ctx echo.Context) er
 		ctx.Request().Header.Get("Hardwire-Dynamic-List-Patch"),
 	)
 	morphSwap := ctx.Request().Header.Get("Hardwire-Htmx-Morph") == "true"
+	routePathname := ctx.Request().Header.Get("Hardwire-Dynamic-Fragment-Request")
 
 	if !utils.IsStatusPositive(ctx.Response().Status) {
 		return nil
 	}
 
-	atomicWriter := &AtomicRespWriter{
-		actionCtx: actx,
-		mutex:     &sync.Mutex{},
+	// pages subscribed to islands depending on this resource
+	// need to be notified about the change
+	if action.Resource != "" {
+		Publish(action.Resource)
 	}
 
 	allIslands := views.GetIslands()
@@ -137,8 +218,11 @@ func (action *Action) Perform(hwContext hw.HardwireContext, ctx echo.Context) er
 			ctx.Logger().Error("fragment not found: ", qi.Island.FragmentID, ", required by island: ", qi.Island.ID)
 			return false
 		}
-		return true
+		// only the islands of the page the action was made from, their
+		// route guards were checked along with the action guards
+		return views.RouteContainsFragment(routePathname, qi.Fragment)
 	})
+	setLoadedListItems(ctx, queuedIslands)
 
 	// if there's only one island to update, do it in the current thread,
 	// don't create new goroutines
@@ -160,6 +244,9 @@ func (action *Action) Perform(hwContext hw.HardwireContext, ctx echo.Context) er
 				func(key string) (interface{}, error) {
 					res, err := actx.HwContext.GetResource(ctx, key)
 					if err != nil {
+						// the island can't be rendered without all of
+						// the resources, the other fetches are canceled
+						actx.cancel(err)
 						return nil, err
 					}
 					resources.Set(key, res)
@@ -188,20 +275,13 @@ func (action *Action) Perform(hwContext hw.HardwireContext, ctx echo.Context) er
 		return err
 	}
 
-	allRequiredResourcesMap := NewMap(map[string]bool{})
-	for qi := range queuedIslands.Iter() {
-		for resKey := range qi.RequiredResources.Iter() {
-			allRequiredResourcesMap.Set(resKey, true)
-		}
-	}
-	allRequiredResources := allRequiredResourcesMap.Keys()
-
-	respMutex := &sync.Mutex{}
-	readyResources := NewMap(map[string]interface{}{})
+	allRequiredResources := requiredResources(queuedIslands)
 
 	// if there's only one resource to retrieve, get it on the current thread
 	// and render islands in separete goroutines
 	if allRequiredResources.Length() == 1 {
+		respMutex := &sync.Mutex{}
+		readyResources := NewMap(map[string]interface{}{})
 		resKey := allRequiredResources.At(0)
 		resource, err := actx.HwContext.GetResource(ctx, resKey)
 		if err != nil {
@@ -218,28 +298,9 @@ func (action *Action) Perform(hwContext hw.HardwireContext, ctx echo.Context) er
 		return nil
 	}
 
-	// retrive each resource in parallel, for each of them, once it's ready:
-	// renderIslands will pick up all isalnds that can be rendered with resources
-	// currently present, render them, write to the response and flush
-	//
-	// renderIslands will process each island in parallel
-	_, errs := utils.InParallel(
-		allRequiredResources.ToSlice(),
-		func(resKey string) (interface{}, error) {
-			res, err := actx.HwContext.GetResource(ctx, resKey)
-			if err != nil {
-				return nil, err
-			}
-			readyResources.Set(resKey, res)
-			errs := renderIslands(
-				actx, respMutex, queuedIslands, readyResources,
-				atomicWriter, morphSwap, itemKeys,
-			)
-			if errs.Length() > 0 {
-				return nil, errors.New("error occurred when rendering some islands")
-			}
-			return nil, nil
-		},
+	errs := fetchAndRenderIslands(
+		actx, queuedIslands, allRequiredResources,
+		atomicWriter, morphSwap, itemKeys,
 	)
 
 	errMsgs := MapTo(NewArray(errs), func(err error) string {
@@ -9,54 +9,77 @@ import (
 )
 
 func RegisterPostAction[T interface{}](
-	resource *ResourceEntry,
+	resource ResourceEntryRef,
 	name string,
 	action func(body *T, ctx *ActionContext) error,
-) {
-	resource.actions.Push(NewAction(name, "POST", action))
+) *Action {
+	return resource.getEntry().pushAction(NewAction(name, "POST", action))
 }
 
 func RegisterPutAction[T interface{}](
-	resource *ResourceEntry,
+	resource ResourceEntryRef,
 	name string,
 	action func(body *T, ctx *ActionContext) error,
-) {
-	resource.actions.Push(NewAction(name, "PUT", action))
+) *Action {
+	return resource.getEntry().pushAction(NewAction(name, "PUT", action))
 }
 
 func RegisterPatchAction[T interface{}](
-	resource *ResourceEntry,
+	resource ResourceEntryRef,
 	name string,
 	action func(body *T, ctx *ActionContext) error,
-) {
-	resource.actions.Push(NewAction(name, "PATCH", action))
+) *Action {
+	return resource.getEntry().pushAction(NewAction(name, "PATCH", action))
 }
 
 func RegisterDeleteAction[T interface{}](
-	resource *ResourceEntry,
+	resource ResourceEntryRef,
 	name string,
 	action func(body *T, ctx *ActionContext) error,
-) {
-	resource.actions.Push(NewAction(name, "DELETE", action))
+) *Action {
+	return resource.getEntry().pushAction(NewAction(name, "DELETE", action))
 }
 
-func MountActionEndpoints(hwContext hw.HardwireContext, server *echo.Echo) {
+type ActionEndpoint struct {
+	Resource string
+	Action   *Action
+	Path     string
+}
+
+func ActionEndpointPath(resourceKey string, actionName string) string {
+	return fmt.Sprintf("/__resources/%s/actions/%s", resourceKey, actionName)
+}
+
+// Returns all the action endpoints registered across all the resources
+func ActionEndpoints() []ActionEndpoint {
+	endpoints := []ActionEndpoint{}
 	ResourceReg.resources.ForEach(func(resourceKey string, entry *ResourceEntry) {
 		entry.actions.ForEach(func(action *Action, idx int) {
-			endpointPath := fmt.Sprintf("/__resources/%s/actions/%s", resourceKey, action.Name)
-			if configuration.Current.DebugMode {
-				fmt.Printf(
-					"Adding action endpoint: %s\n",
-					endpointPath,
-				)
-			}
-			server.Add(
-				action.Method,
-				endpointPath,
-				func(ctx echo.Context) error {
-					return action.Perform(hwContext, ctx)
-				},
-			)
+			endpoints = append(endpoints, ActionEndpoint{
+				Resource: resourceKey,
+				Action:   action,
+				Path:     ActionEndpointPath(resourceKey, action.Name),
+			})
 		})
 	})
+	return endpoints
+}
+
+func MountActionEndpoints(hwContext hw.HardwireContext, server *echo.Echo) {
+	for _, endpoint := range ActionEndpoints() {
+		action := endpoint.Action
+		if configuration.Current.DebugMode {
+			fmt.Printf(
+				"Adding action endpoint: %s\n",
+				endpoint.Path,
+			)
+		}
+		server.Add(
+			action.Method,
+			endpoint.Path,
+			func(ctx echo.Context) error {
+				return action.Perform(hwContext, ctx)
+			},
+		)
+	}
 }
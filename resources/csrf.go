@@ -0,0 +1,163 @@
+package resourceprovider
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/configuration"
+	"github.com/ncpa0/hardwire/utils"
+)
+
+var generatedSecret []byte
+var generatedSecretOnce = &sync.Once{}
+
+func csrfSecret() []byte {
+	if len(configuration.Current.CSRF.Secret) > 0 {
+		return configuration.Current.CSRF.Secret
+	}
+	generatedSecretOnce.Do(func() {
+		generatedSecret = make([]byte, 32)
+		rand.Read(generatedSecret)
+	})
+	return generatedSecret
+}
+
+func signCSRF(nonce string, issuedAt int64) string {
+	mac := hmac.New(sha256.New, csrfSecret())
+	mac.Write([]byte(fmt.Sprintf("%s.%d", nonce, issuedAt)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Issues a new token for the request, setting the cookie it's bound to
+// if it's not present yet. Returns an empty string when the CSRF
+// protection is disabled.
+//
+// The cookie holds a random value, and the token is the time it was
+// issued at along with the signature of both, so a valid token can't be
+// produced without the cookie, and tokens issued for one browser can't
+// be used by another.
+func CSRFToken(c echo.Context) string {
+	conf := configuration.Current.CSRF
+	if conf.Disabled {
+		return ""
+	}
+
+	nonce := ""
+	if cookie, err := c.Cookie(conf.CookieName); err == nil {
+		nonce = cookie.Value
+	}
+	if nonce == "" {
+		b := make([]byte, 32)
+		rand.Read(b)
+		nonce = base64.RawURLEncoding.EncodeToString(b)
+		c.SetCookie(&http.Cookie{
+			Name:     conf.CookieName,
+			Value:    nonce,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   c.IsTLS(),
+			SameSite: http.SameSiteLaxMode,
+		})
+		// make the cookie visible to anything else issuing
+		// tokens within this request
+		c.Request().AddCookie(&http.Cookie{Name: conf.CookieName, Value: nonce})
+	}
+
+	issuedAt := time.Now().Unix()
+	return fmt.Sprintf("%d.%s", issuedAt, signCSRF(nonce, issuedAt))
+}
+
+// Checks the token sent in the request header (or form field) against the
+// cookie it was issued for.
+func verifyCSRF(c echo.Context) error {
+	conf := configuration.Current.CSRF
+
+	token := c.Request().Header.Get(conf.HeaderName)
+	if token == "" {
+		token = c.FormValue(conf.FieldName)
+	}
+
+	cookie, err := c.Cookie(conf.CookieName)
+	if err != nil || cookie.Value == "" || token == "" {
+		return csrfError(c)
+	}
+
+	issuedAtStr, signature, found := strings.Cut(token, ".")
+	if !found {
+		return csrfError(c)
+	}
+	issuedAt, err := strconv.ParseInt(issuedAtStr, 10, 64)
+	if err != nil {
+		return csrfError(c)
+	}
+
+	age := time.Since(time.Unix(issuedAt, 0))
+	if age > conf.TokenTTL || age < -time.Minute {
+		return csrfError(c)
+	}
+
+	expected := signCSRF(cookie.Value, issuedAt)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return csrfError(c)
+	}
+
+	return nil
+}
+
+// The token cookie gets dropped so that the page asks for a new token,
+// htmx requests also reload the page.
+func csrfError(c echo.Context) error {
+	c.SetCookie(&http.Cookie{
+		Name:   configuration.Current.CSRF.TokenCookieName,
+		Path:   "/",
+		MaxAge: -1,
+	})
+	if c.Request().Header.Get("Hx-Request") != "" {
+		utils.Htmx(c).Refresh()
+	}
+	return echo.NewHTTPError(http.StatusForbidden, "invalid or missing CSRF token")
+}
+
+// Issues a token and stores it in a cookie the page script can read. The
+// cookie expires a bit before the token does, so the script never sends
+// an expired token.
+func csrfTokenHandler(c echo.Context) error {
+	conf := configuration.Current.CSRF
+	token := CSRFToken(c)
+
+	maxAge := int((conf.TokenTTL - time.Minute).Seconds())
+	if maxAge < 1 {
+		maxAge = 1
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     conf.TokenCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   c.IsTLS(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.String(http.StatusOK, token)
+}
+
+func MountCSRFEndpoint(server *echo.Echo) {
+	if configuration.Current.CSRF.Disabled {
+		return
+	}
+
+	endpointPath := configuration.Current.CSRF.TokenURL
+	if configuration.Current.DebugMode {
+		fmt.Printf("Adding CSRF token endpoint: %s\n", endpointPath)
+	}
+	server.GET(endpointPath, csrfTokenHandler)
+}
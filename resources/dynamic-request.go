@@ -1,6 +1,7 @@
 package resourceprovider
 
 import (
+	"context"
 	"net/http"
 
 	echo "github.com/labstack/echo/v4"
@@ -11,6 +12,10 @@ type DynamicRequestContext struct {
 	Echo          echo.Context
 	params        map[string]string
 	routePathname string
+	resourceName  string
+	// set while the resource is fetched with a timeout
+	reqCtx       context.Context
+	dependencies map[string]interface{}
 }
 
 func NewDynamicRequestContext(echo echo.Context, params map[string]string, routePathname string) *DynamicRequestContext {
@@ -31,6 +36,16 @@ func (ctx *DynamicRequestContext) GetRoutePath() string {
 	return ctx.routePathname
 }
 
+// Returns the name of the resource being fetched
+func (ctx *DynamicRequestContext) GetResourceName() string {
+	return ctx.resourceName
+}
+
+// Sets the htmx response headers of the request fetching the resource
+func (ctx *DynamicRequestContext) Htmx() *utils.HtmxHeaders {
+	return utils.Htmx(ctx.Echo)
+}
+
 func (ctx *DynamicRequestContext) Err(code int, message string) *ResourceRequestError {
 	return &ResourceRequestError{
 		errType: "error",
@@ -59,10 +74,10 @@ type ResourceRequestError struct {
 func (err *ResourceRequestError) SendResponse(c echo.Context) error {
 	switch err.errType {
 	case "error":
-		return c.String(err.Code, err.Data)
+		return utils.RenderError(c, err.Code, err.Data)
 	case "redirect":
 		if c.Request().Header.Get("Hx-Request") != "" {
-			c.Response().Header().Set("Hx-Redirect", err.Data)
+			utils.Htmx(c).Redirect(err.Data)
 			return c.NoContent(200)
 		}
 		return c.Redirect(err.Code, err.Data)
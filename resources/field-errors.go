@@ -0,0 +1,74 @@
+package resourceprovider
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/utils"
+)
+
+// Responds with the binding or validation errors. For htmx requests the
+// errors are swapped into the error slots of the form that sent the
+// request (elements with a `data-field-error` attribute set to the field
+// name, and elements with a `data-form-error` attribute for errors not
+// related to any field), while the form itself is left as is, keeping
+// the user's input.
+//
+// The form is identified by the `Hardwire-Form` header sent by the
+// generated forms, or the id of the element that made the request.
+func sendFieldErrors(ctx echo.Context, errs utils.FieldErrors) error {
+	req := ctx.Request()
+	if req.Header.Get("Hx-Request") == "" {
+		return errs.SendResponse(ctx)
+	}
+
+	formID := req.Header.Get("Hardwire-Form")
+	if formID == "" {
+		formID = req.Header.Get("Hx-Trigger")
+	}
+	scope := ""
+	if formID != "" {
+		scope = "#" + formID + " "
+	}
+
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	swaps := []string{}
+	// errors from the previous submissions get cleared first, unless
+	// the form is unknown, the slots of other forms are left alone
+	if scope != "" {
+		swaps = append(swaps,
+			errorSlotSwap(scope+"[data-field-error]", ""),
+			errorSlotSwap(scope+"[data-form-error]", ""),
+		)
+	}
+	for _, field := range fields {
+		selector := scope + "[data-form-error]"
+		if field != "" {
+			selector = fmt.Sprintf("%s[data-field-error='%s']", scope, field)
+		}
+		swaps = append(swaps, errorSlotSwap(selector, errs[field]))
+	}
+
+	utils.Htmx(ctx).Reswap("none")
+	return ctx.HTML(http.StatusOK, strings.Join(swaps, "\n"))
+}
+
+func errorSlotSwap(selector string, message string) string {
+	swap := utils.OobSwap{
+		Mode:     "innerHTML",
+		Selector: selector,
+	}
+	return fmt.Sprintf(
+		"<div hx-swap-oob=\"%s\">%s</div>",
+		html.EscapeString(swap.Build()), html.EscapeString(message),
+	)
+}
@@ -0,0 +1,53 @@
+package resourceprovider_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	resources "github.com/ncpa0/hardwire/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+type fieldErrorsTestBody struct {
+	Title string `form:"title" validate:"required"`
+}
+
+func TestFieldErrorsAreScopedToTheForm(t *testing.T) {
+	ass := assert.New(t)
+	disableCSRF(t)
+
+	entry := resources.ResourceReg.Register("field-errors-test", &idempotencyTestResource{})
+	resources.RegisterPostAction(entry, "create", func(body *fieldErrorsTestBody, ctx *resources.ActionContext) error {
+		return nil
+	})
+
+	server := echo.New()
+	server.Logger.SetOutput(io.Discard)
+	resources.MountActionEndpoints(nil, server)
+
+	send := func(headers map[string]string) string {
+		req := httptest.NewRequest(http.MethodPost, resources.ActionEndpointPath("field-errors-test", "create"), strings.NewReader("title="))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		req.Header.Set("Hx-Request", "true")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		ass.Equal(http.StatusOK, rec.Code)
+		return rec.Body.String()
+	}
+
+	body := send(map[string]string{"Hardwire-Form": "form_1", "Hx-Trigger": "submit"})
+	ass.Contains(body, "innerHTML:#form_1 [data-field-error]\"")
+	ass.Contains(body, "innerHTML:#form_1 [data-field-error=&#39;title&#39;]")
+
+	// the error slots of the other forms on the page are left alone
+	body = send(map[string]string{})
+	ass.NotContains(body, "[data-field-error]\"")
+	ass.Contains(body, "innerHTML:[data-field-error=&#39;title&#39;]")
+}
@@ -0,0 +1,227 @@
+package resourceprovider
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/configuration"
+	"github.com/ncpa0/hardwire/utils"
+)
+
+// Everything a guard can decide on. Fields that don't apply to the
+// guarded request (e.g. the `Action` when a page is requested) are empty.
+type GuardContext struct {
+	Echo echo.Context
+	// Route pathname of the page the request was made from
+	Route    string
+	Resource string
+	Action   string
+}
+
+// Returns the identity of the client, as resolved by the configured
+// `Auth.Identity` function. Nil if there's no such function.
+func (ctx *GuardContext) Identity() (interface{}, error) {
+	return GetIdentity(ctx.Echo)
+}
+
+// Allows the request by returning nil, or denies it by returning one of
+// `Unauthorized()`, `Forbidden()` or `RedirectTo()`. Any other error is
+// handled as an internal error.
+type Guard func(ctx *GuardContext) error
+
+type GuardDenial struct {
+	Code     int
+	Message  string
+	Location string
+}
+
+func (d *GuardDenial) Error() string {
+	if d.Location != "" {
+		return "access denied, redirecting to " + d.Location
+	}
+	return "access denied: " + d.Message
+}
+
+func (d *GuardDenial) SendResponse(c echo.Context) error {
+	location := d.Location
+	switch d.Code {
+	case http.StatusUnauthorized:
+		location = configuration.Current.Auth.UnauthorizedRedirect
+	case http.StatusForbidden:
+		location = configuration.Current.Auth.ForbiddenRedirect
+	}
+
+	if location == "" {
+		return utils.RenderError(c, d.Code, d.Message)
+	}
+	if c.Request().Header.Get("Hx-Request") != "" {
+		utils.Htmx(c).Redirect(location)
+		return c.NoContent(http.StatusOK)
+	}
+	return c.Redirect(http.StatusSeeOther, location)
+}
+
+func Unauthorized() *GuardDenial {
+	return &GuardDenial{Code: http.StatusUnauthorized, Message: "Unauthorized"}
+}
+
+func Forbidden() *GuardDenial {
+	return &GuardDenial{Code: http.StatusForbidden, Message: "Forbidden"}
+}
+
+func RedirectTo(location string) *GuardDenial {
+	return &GuardDenial{Code: http.StatusSeeOther, Location: location}
+}
+
+type resolvedIdentity struct {
+	once     *sync.Once
+	identity interface{}
+	err      error
+}
+
+var identityMutex = &sync.Mutex{}
+
+const identityKey = "hardwire.identity"
+
+// Returns the identity of the client making the request, resolved with
+// the configured `Auth.Identity` function the first time it's needed.
+func GetIdentity(c echo.Context) (interface{}, error) {
+	resolve := configuration.Current.Auth.Identity
+	if resolve == nil {
+		return nil, nil
+	}
+
+	identityMutex.Lock()
+	resolved, ok := c.Get(identityKey).(*resolvedIdentity)
+	if !ok {
+		resolved = &resolvedIdentity{once: &sync.Once{}}
+		c.Set(identityKey, resolved)
+	}
+	identityMutex.Unlock()
+
+	resolved.once.Do(func() {
+		resolved.identity, resolved.err = resolve(c)
+	})
+	return resolved.identity, resolved.err
+}
+
+type routeGuard struct {
+	pattern string
+	guards  []Guard
+}
+
+var routeGuards = []*routeGuard{}
+
+// Guards all the pages matching the pattern, along with their fragments
+// and island subscriptions. Patterns are matched segment by segment, a
+// `*` segment matches any single segment, or the rest of the path if it's
+// the last one (e.g. `/admin/*`), route parameters match any segment.
+func GuardRoute(pattern string, guards ...Guard) {
+	routeGuards = append(routeGuards, &routeGuard{
+		pattern: pattern,
+		guards:  guards,
+	})
+}
+
+func matchRoutePattern(pattern string, route string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	routeSegments := strings.Split(strings.Trim(route, "/"), "/")
+
+	for i, seg := range patternSegments {
+		if seg == "*" && i == len(patternSegments)-1 {
+			return true
+		}
+		if i >= len(routeSegments) {
+			return false
+		}
+		routeSeg := routeSegments[i]
+		if seg != "*" && seg != routeSeg && !strings.HasPrefix(seg, ":") && !strings.HasPrefix(routeSeg, ":") {
+			return false
+		}
+	}
+
+	return len(patternSegments) == len(routeSegments)
+}
+
+func runGuards(ctx *GuardContext, guards []Guard) error {
+	for _, guard := range guards {
+		err := guard(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Runs the guards of all the route patterns matching the page route
+func CheckRouteGuards(c echo.Context, route string) error {
+	ctx := &GuardContext{Echo: c, Route: route}
+	for _, rg := range routeGuards {
+		if !matchRoutePattern(rg.pattern, route) {
+			continue
+		}
+		err := runGuards(ctx, rg.guards)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Runs the guards registered on the resource, before it gets fetched
+func CheckResourceGuards(c echo.Context, route string, entry *ResourceEntry) error {
+	return runGuards(&GuardContext{
+		Echo:     c,
+		Route:    route,
+		Resource: entry.name,
+	}, entry.guards)
+}
+
+// Adds guards checked before the resource is fetched (for pages, fragments
+// and islands alike) and before any of its actions is performed.
+func (entry *ResourceEntry) Guard(guards ...Guard) *ResourceEntry {
+	entry.guards = append(entry.guards, guards...)
+	return entry
+}
+
+// Adds guards checked before the action is performed, after the guards
+// of the action's resource.
+func (action *Action) Guard(guards ...Guard) *Action {
+	action.guards = append(action.guards, guards...)
+	return action
+}
+
+// Runs the guards of the page the action was made from, then the guards
+// of the action's resource and the action itself. The page route comes
+// from the client, actions used on any page can only be performed with
+// the route of one of those pages.
+func checkActionGuards(c echo.Context, action *Action) error {
+	ctx := &GuardContext{
+		Echo:     c,
+		Route:    c.Request().Header.Get("Hardwire-Dynamic-Fragment-Request"),
+		Resource: action.Resource,
+		Action:   action.Name,
+	}
+
+	if len(action.routes) > 0 && !slices.Contains(action.routes, ctx.Route) {
+		return Forbidden()
+	}
+	if ctx.Route != "" {
+		err := CheckRouteGuards(c, ctx.Route)
+		if err != nil {
+			return err
+		}
+	}
+
+	entry, found := ResourceReg.find(action.Resource)
+	if found {
+		err := runGuards(ctx, entry.guards)
+		if err != nil {
+			return err
+		}
+	}
+	return runGuards(ctx, action.guards)
+}
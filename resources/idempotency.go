@@ -0,0 +1,181 @@
+package resourceprovider
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/configuration"
+)
+
+type recordedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+type idempotentRequest struct {
+	// closed once the first request is finished
+	done      chan struct{}
+	response  *recordedResponse
+	expiresAt time.Time
+}
+
+var idempotentRequests = map[string]*idempotentRequest{}
+var idempotencyMutex = &sync.Mutex{}
+var lastIdempotencySweep = time.Now()
+
+// Copies everything written to the response, including the streamed
+// island updates, so that it can be replayed later
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	rec.body.Write(data)
+	return rec.ResponseWriter.Write(data)
+}
+
+func (rec *responseRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func idempotencyKey(c echo.Context) string {
+	conf := configuration.Current.Idempotency
+	key := c.Request().Header.Get(conf.HeaderName)
+	if key == "" {
+		key = c.FormValue(conf.FieldName)
+	}
+	return key
+}
+
+// Returns the request registered under the key, or registers a new one,
+// in which case the returned bool is true and the caller is responsible
+// for performing it.
+func acquireIdempotentRequest(key string) (*idempotentRequest, bool) {
+	idempotencyMutex.Lock()
+	defer idempotencyMutex.Unlock()
+
+	sweepIdempotentRequests(time.Now())
+
+	if req, ok := idempotentRequests[key]; ok {
+		return req, false
+	}
+
+	req := &idempotentRequest{done: make(chan struct{})}
+	idempotentRequests[key] = req
+	return req, true
+}
+
+// Drops the expired responses, at most once a minute. Must be called
+// with the mutex locked.
+func sweepIdempotentRequests(now time.Time) {
+	if now.Sub(lastIdempotencySweep) < time.Minute {
+		return
+	}
+	lastIdempotencySweep = now
+	for key, req := range idempotentRequests {
+		if req.response != nil && req.expiresAt.Before(now) {
+			delete(idempotentRequests, key)
+		}
+	}
+}
+
+func finishIdempotentRequest(key string, req *idempotentRequest, response *recordedResponse) {
+	idempotencyMutex.Lock()
+	if response == nil {
+		// failed requests are not remembered, the duplicates
+		// get performed again
+		delete(idempotentRequests, key)
+	} else {
+		req.response = response
+		req.expiresAt = time.Now().Add(configuration.Current.Idempotency.TTL)
+	}
+	idempotencyMutex.Unlock()
+	close(req.done)
+}
+
+// Performs the request once for the given key. Duplicates received while
+// it's in progress wait for it to finish, then all the duplicates get the
+// recorded response of the first one.
+func performIdempotent(c echo.Context, key string, perform func() error) error {
+	for {
+		req, isFirst := acquireIdempotentRequest(key)
+		if !isFirst {
+			select {
+			case <-req.done:
+			case <-c.Request().Context().Done():
+				return c.Request().Context().Err()
+			}
+			if req.response == nil {
+				continue
+			}
+			return replayResponse(c, req.response)
+		}
+
+		return performRecorded(c, key, req, perform)
+	}
+}
+
+// Performs the first request of the key and records its response. The
+// key gets released even if the request panics, otherwise its duplicates
+// would wait for it forever.
+func performRecorded(c echo.Context, key string, req *idempotentRequest, perform func() error) (err error) {
+	resp := c.Response()
+	recorder := &responseRecorder{ResponseWriter: resp.Writer}
+	resp.Writer = recorder
+
+	var recorded *recordedResponse
+	defer func() {
+		resp.Writer = recorder.ResponseWriter
+		finishIdempotentRequest(key, req, recorded)
+	}()
+
+	err = perform()
+	if err != nil || resp.Status >= http.StatusInternalServerError {
+		return err
+	}
+
+	recorded = &recordedResponse{
+		status: resp.Status,
+		header: resp.Header().Clone(),
+		body:   recorder.body.Bytes(),
+	}
+	return nil
+}
+
+// Headers of the recorded response that are not replayed, the cookies
+// (e.g. the session or the CSRF token) belong to the client that made
+// the first request, and the hop-by-hop headers to its connection.
+var unreplayedHeaders = []string{
+	"Set-Cookie",
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func replayResponse(c echo.Context, recorded *recordedResponse) error {
+	resp := c.Response()
+	for key, values := range recorded.header {
+		if slices.Contains(unreplayedHeaders, http.CanonicalHeaderKey(key)) {
+			continue
+		}
+		resp.Header()[key] = values
+	}
+	resp.Header().Set("Idempotent-Replayed", "true")
+	resp.WriteHeader(recorded.status)
+	_, err := resp.Write(recorded.body)
+	return err
+}
@@ -0,0 +1,153 @@
+package resourceprovider_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	resources "github.com/ncpa0/hardwire/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+type idempotencyTestResource struct{}
+
+func (r *idempotencyTestResource) Get(c *resources.DynamicRequestContext) (interface{}, error) {
+	return nil, nil
+}
+
+type idempotencyTestBody struct{}
+
+func newIdempotencyTestServer(
+	t *testing.T,
+	name string,
+	handler func(body *idempotencyTestBody, ctx *resources.ActionContext) error,
+) *echo.Echo {
+	disableCSRF(t)
+
+	entry := resources.ResourceReg.Register(name, &idempotencyTestResource{})
+	resources.RegisterPostAction(entry, "run", handler)
+
+	server := echo.New()
+	server.Logger.SetOutput(io.Discard)
+	server.Use(middleware.Recover())
+	resources.MountActionEndpoints(nil, server)
+	return server
+}
+
+func sendIdempotent(server *echo.Echo, name string, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, resources.ActionEndpointPath(name, "run"), nil)
+	req.Header.Set("Idempotency-Key", key)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyDuplicateWaitsAndReplays(t *testing.T) {
+	ass := assert.New(t)
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := newIdempotencyTestServer(t, "idempotency-wait", func(body *idempotencyTestBody, ctx *resources.ActionContext) error {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		return nil
+	})
+
+	var first, second *httptest.ResponseRecorder
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		first = sendIdempotent(server, "idempotency-wait", "key-1")
+	}()
+	<-started
+	go func() {
+		defer wg.Done()
+		second = sendIdempotent(server, "idempotency-wait", "key-1")
+	}()
+
+	// the duplicate is waiting for the first request
+	time.Sleep(50 * time.Millisecond)
+	ass.Equal(int32(1), calls.Load())
+
+	close(release)
+	wg.Wait()
+
+	ass.Equal(int32(1), calls.Load())
+	ass.Equal(http.StatusNoContent, first.Code)
+	ass.Equal(http.StatusNoContent, second.Code)
+	ass.Equal("", first.Header().Get("Idempotent-Replayed"))
+	ass.Equal("true", second.Header().Get("Idempotent-Replayed"))
+
+	// later duplicates get the recorded response too
+	third := sendIdempotent(server, "idempotency-wait", "key-1")
+	ass.Equal(int32(1), calls.Load())
+	ass.Equal("true", third.Header().Get("Idempotent-Replayed"))
+
+	// other keys are performed
+	sendIdempotent(server, "idempotency-wait", "key-2")
+	ass.Equal(int32(2), calls.Load())
+}
+
+func TestIdempotencyFailedRequestsAreForgotten(t *testing.T) {
+	ass := assert.New(t)
+
+	var calls atomic.Int32
+	server := newIdempotencyTestServer(t, "idempotency-fail", func(body *idempotencyTestBody, ctx *resources.ActionContext) error {
+		switch calls.Add(1) {
+		case 1:
+			return errors.New("failed")
+		case 2:
+			panic("failed")
+		}
+		return nil
+	})
+
+	rec := sendIdempotent(server, "idempotency-fail", "key")
+	ass.Equal(http.StatusInternalServerError, rec.Code)
+
+	rec = sendIdempotent(server, "idempotency-fail", "key")
+	ass.Equal(http.StatusInternalServerError, rec.Code)
+
+	// neither the error nor the panic left the key in flight
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- sendIdempotent(server, "idempotency-fail", "key")
+	}()
+	select {
+	case rec = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the duplicate request is waiting for a failed one")
+	}
+	ass.Equal(int32(3), calls.Load())
+	ass.Equal(http.StatusNoContent, rec.Code)
+	ass.Equal("", rec.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotencyReplayDropsCookies(t *testing.T) {
+	ass := assert.New(t)
+
+	server := newIdempotencyTestServer(t, "idempotency-cookies", func(body *idempotencyTestBody, ctx *resources.ActionContext) error {
+		ctx.Echo.SetCookie(&http.Cookie{Name: "session", Value: "first-client"})
+		ctx.Echo.Response().Header().Set("Hx-Trigger", "saved")
+		return nil
+	})
+
+	first := sendIdempotent(server, "idempotency-cookies", "key")
+	ass.NotEmpty(first.Header().Get("Set-Cookie"))
+
+	replayed := sendIdempotent(server, "idempotency-cookies", "key")
+	ass.Equal("true", replayed.Header().Get("Idempotent-Replayed"))
+	ass.Equal("saved", replayed.Header().Get("Hx-Trigger"))
+	ass.Empty(replayed.Header().Get("Set-Cookie"))
+}
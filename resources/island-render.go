@@ -1,7 +1,7 @@
 package resourceprovider
 
 import (
-	"fmt"
+	"errors"
 	"strings"
 	"sync"
 
@@ -34,55 +34,29 @@ func sendIslandUpdate(
 		return echo.ErrInternalServerError
 	}
 
-	if itemKeys.Length() == 0 || island.Type != "list" {
-		fragmentNode = xmlquery.FindOne(
-			fragmentNode, "//div[@data-frag-url]",
-		)
-
-		swap.Selector = "#" + island.ID
-		utils.XmlNodeSetAttribute(
-			fragmentNode,
-			"hx-swap-oob",
-			swap.Build(),
-		)
-		nodeHtml := utils.XmlNodeToString(fragmentNode)
-
-		writer.Write(island.ID, []byte(nodeHtml))
-	} else {
-		items := NewArray([]string{})
-		for itemKey := range itemKeys.Iter() {
-			itemNode, err := xmlquery.Query(
-				fragmentNode, fmt.Sprintf("//div[@data-item-key=\"%s\"]", itemKey),
-			)
-			if err == nil && itemNode != nil {
-				swap.Selector = fmt.Sprintf(
-					".island_%s .dynamic-list-element[data-item-key='%s']",
-					island.ID, itemKey,
-				)
-				utils.XmlNodeSetAttribute(
-					itemNode,
-					"hx-swap-oob",
-					swap.Build(),
-				)
-				items.Push(utils.XmlNodeToString(itemNode))
-			} else {
-				swap := utils.OobSwap{
-					Mode: "delete",
-					Selector: fmt.Sprintf(
-						".island_%s .dynamic-list-element[data-item-key='%s']",
-						island.ID, itemKey,
-					),
-				}
-				items.Push(fmt.Sprintf(
-					"<div hx-swap-oob=\"%s\"></div>",
-					swap.Build(),
-				))
-			}
-		}
-		writer.Write(island.ID, []byte("\n"+strings.Join(items.ToSlice(), "\n")))
+	kind, ok := views.GetIslandKind(island.Type)
+	if !ok {
+		ctx.Logger().Error("unknown island kind: ", island.Type, ", of island: ", island.ID)
+		return echo.ErrInternalServerError
 	}
 
-	return nil
+	clientKeys := utils.ParseListOrderHeader(
+		ctx.Request().Header.Get("Hardwire-Dynamic-List-Order"),
+	)[island.ID]
+
+	swapHtml, err := kind.BuildSwap(&views.IslandUpdate{
+		Island:     island,
+		Fragment:   fragmentNode,
+		Swap:       swap,
+		ItemKeys:   itemKeys,
+		ClientKeys: clientKeys,
+	})
+	if err != nil {
+		ctx.Logger().Error("error building island update: ", err)
+		return echo.ErrInternalServerError
+	}
+
+	return writer.Write(island.ID, []byte(swapHtml))
 }
 
 func renderIslands(
@@ -96,17 +70,20 @@ func renderIslands(
 ) *Array[error] {
 	mutex.Lock()
 	qisBatch := NewArray([]*QueuedIsland{})
-	islands = islands.Filter(func(qi *QueuedIsland, idx int) bool {
+	islands.Remove(func(qi *QueuedIsland, idx int) bool {
 		if qi.CanRender(resources) {
 			qisBatch.Push(qi)
-			return false
+			return true
 		}
-		return true
+		return false
 	})
 	mutex.Unlock()
 
 	ops := MapTo(qisBatch, func(qi *QueuedIsland) *Promise.Promise[interface{}] {
 		return Promise.New(func() (interface{}, error) {
+			if err := actx.Context().Err(); err != nil {
+				return nil, err
+			}
 			html, err := actx.HwContext.BuildFragment(qi.Fragment, resources)
 			if err != nil {
 				actx.Echo.Logger().Errorf(
@@ -131,3 +108,57 @@ func renderIslands(
 		return res.Err
 	})
 }
+
+// Returns the keys of all the resources required by the given islands
+func requiredResources(islands *Array[*QueuedIsland]) *Array[string] {
+	resourcesMap := NewMap(map[string]bool{})
+	for qi := range islands.Iter() {
+		for resKey := range qi.RequiredResources.Iter() {
+			resourcesMap.Set(resKey, true)
+		}
+	}
+	return resourcesMap.Keys()
+}
+
+// Retrieves each of the resources in parallel, for each of them, once it's
+// ready: renderIslands will pick up all isalnds that can be rendered with
+// resources currently present, render them, write to the response and flush
+//
+// renderIslands will process each island in parallel
+func fetchAndRenderIslands(
+	actx *ActionContext,
+	islands *Array[*QueuedIsland],
+	resourceKeys *Array[string],
+	writer RespWriter,
+	morphSwap bool,
+	itemKeys *Array[string],
+) []error {
+	respMutex := &sync.Mutex{}
+	readyResources := NewMap(map[string]interface{}{})
+
+	_, errs := utils.InParallel(
+		resourceKeys.ToSlice(),
+		func(resKey string) (interface{}, error) {
+			if err := actx.Context().Err(); err != nil {
+				return nil, err
+			}
+			res, err := actx.HwContext.GetResource(actx.Echo, resKey)
+			if err != nil {
+				return nil, err
+			}
+			respMutex.Lock()
+			readyResources.Set(resKey, res)
+			respMutex.Unlock()
+			errs := renderIslands(
+				actx, respMutex, islands, readyResources,
+				writer, morphSwap, itemKeys,
+			)
+			if errs.Length() > 0 {
+				return nil, errors.New("error occurred when rendering some islands")
+			}
+			return nil, nil
+		},
+	)
+
+	return errs
+}
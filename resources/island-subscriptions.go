@@ -0,0 +1,522 @@
+package resourceprovider
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/configuration"
+	hw "github.com/ncpa0/hardwire/hw-context"
+	"github.com/ncpa0/hardwire/utils"
+	"github.com/ncpa0/hardwire/views"
+	. "github.com/ncpa0cpl/ezs"
+)
+
+type sseEvent struct {
+	id   string
+	data []byte
+}
+
+// Most recent events sent to a client, replayed when the client
+// reconnects with a `Last-Event-ID`. The same log can be shared by
+// multiple connections of the client, e.g. while the old one is not
+// closed yet.
+type replayLog struct {
+	mutex     sync.Mutex
+	clientID  string
+	seq       uint64
+	events    []*sseEvent
+	expiresAt time.Time
+	active    int
+}
+
+func (log *replayLog) append(data []byte) *sseEvent {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	log.seq++
+	event := &sseEvent{
+		id:   fmt.Sprintf("%s-%d", log.clientID, log.seq),
+		data: data,
+	}
+	log.events = append(log.events, event)
+	if len(log.events) > configuration.Current.Realtime.ReplayBufferSize {
+		log.events = log.events[len(log.events)-configuration.Current.Realtime.ReplayBufferSize:]
+	}
+	return event
+}
+
+func (log *replayLog) since(seq uint64) []*sseEvent {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	result := []*sseEvent{}
+	for _, event := range log.events {
+		if eventSeq(event.id) > seq {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+func eventSeq(eventID string) uint64 {
+	idx := strings.LastIndex(eventID, "-")
+	seq, _ := strconv.ParseUint(eventID[idx+1:], 10, 64)
+	return seq
+}
+
+// Writes the island updates to the event stream, each update is
+// recorded in the client's replay log. Once closed, nothing gets written
+// anymore, the context can already be serving another request.
+type sseWriter struct {
+	ctx    echo.Context
+	log    *replayLog
+	mutex  *sync.Mutex
+	closed bool
+}
+
+var errStreamClosed = errors.New("the event stream is closed")
+
+func (w *sseWriter) Write(islandID string, data []byte) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return errStreamClosed
+	}
+	event := w.log.append(data)
+	return w.send(event)
+}
+
+func (w *sseWriter) close() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.closed = true
+}
+
+// Must be called with the mutex locked
+func (w *sseWriter) send(event *sseEvent) error {
+	if w.closed {
+		return errStreamClosed
+	}
+
+	var sb strings.Builder
+	sb.WriteString("id: " + event.id + "\n")
+	sb.WriteString("event: island\n")
+	for _, line := range strings.Split(string(event.data), "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+
+	resp := w.ctx.Response()
+	_, err := resp.Write([]byte(sb.String()))
+	if err != nil {
+		return err
+	}
+	resp.Flush()
+	return nil
+}
+
+func (w *sseWriter) heartbeat() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return errStreamClosed
+	}
+	resp := w.ctx.Response()
+	_, err := resp.Write([]byte(": heartbeat\n\n"))
+	if err != nil {
+		return err
+	}
+	resp.Flush()
+	return nil
+}
+
+// A single page connection, subscribed to updates of the islands it shows
+type islandSubscription struct {
+	actx *ActionContext
+	// route of the page the subscription was made from
+	route     string
+	islands   *Array[*QueuedIsland]
+	writer    RespWriter
+	morphSwap bool
+	closed    bool
+	onClose   func()
+	// called when the guards no longer let the subscriber receive
+	// the updates
+	onDenied func(err error)
+	// refreshes in progress, they use the request context, so the
+	// handler can't return before they're done
+	running sync.WaitGroup
+	// refreshes share the request context and its resolver, so only
+	// one of them runs at a time
+	refreshMutex sync.Mutex
+}
+
+// Re-renders all the subscribed islands that depend on any of the
+// given resources.
+func (sub *islandSubscription) refresh(resourceKeys []string) {
+	defer sub.running.Done()
+	sub.refreshMutex.Lock()
+	defer sub.refreshMutex.Unlock()
+
+	islands := sub.islands.Filter(func(qi *QueuedIsland, _ int) bool {
+		return qi.RequiredResources.Some(func(resKey string, _ int) bool {
+			return Contains(NewArray(resourceKeys), resKey)
+		})
+	})
+	if islands.Length() == 0 {
+		return
+	}
+
+	// the subscription outlives the request, resources fetched for the
+	// previous update are outdated
+	resetResolver(sub.actx.Echo)
+
+	// the subscriber could have logged out or lost the access since
+	// the subscription was made
+	err := checkSubscriptionGuards(sub.actx.Echo, sub.route, islands)
+	if err != nil {
+		if sub.onDenied != nil {
+			sub.onDenied(err)
+		}
+		return
+	}
+
+	errs := fetchAndRenderIslands(
+		sub.actx, islands, requiredResources(islands),
+		sub.writer, sub.morphSwap, NewArray([]string{}),
+	)
+	for _, err := range errs {
+		sub.actx.Echo.Logger().Error("error refreshing subscribed islands: ", err)
+	}
+}
+
+func (sub *islandSubscription) dependsOn(resourceKeys []string) bool {
+	return sub.islands.Some(func(qi *QueuedIsland, _ int) bool {
+		return qi.RequiredResources.Some(func(resKey string, _ int) bool {
+			return Contains(NewArray(resourceKeys), resKey)
+		})
+	})
+}
+
+type subscriptionHub struct {
+	mutex         *sync.Mutex
+	subscriptions map[*islandSubscription]bool
+	logs          map[string]*replayLog
+}
+
+var islandHub = &subscriptionHub{
+	mutex:         &sync.Mutex{},
+	subscriptions: map[*islandSubscription]bool{},
+	logs:          map[string]*replayLog{},
+}
+
+func (hub *subscriptionHub) add(sub *islandSubscription) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	hub.subscriptions[sub] = true
+}
+
+func (hub *subscriptionHub) remove(sub *islandSubscription) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	sub.closed = true
+	delete(hub.subscriptions, sub)
+	if sub.onClose != nil {
+		sub.onClose()
+	}
+}
+
+// Returns the replay log of the given client, creating a new one if
+// it doesn't exist. Logs of clients that disconnected a while ago
+// are dropped.
+func (hub *subscriptionHub) replayLog(clientID string) *replayLog {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	now := time.Now()
+	for id, log := range hub.logs {
+		if log.active == 0 && log.expiresAt.Before(now) {
+			delete(hub.logs, id)
+		}
+	}
+
+	log, ok := hub.logs[clientID]
+	if !ok {
+		log = &replayLog{clientID: clientID}
+		hub.logs[clientID] = log
+	}
+	log.active++
+	return log
+}
+
+func (hub *subscriptionHub) publish(resourceKeys []string) {
+	hub.mutex.Lock()
+	subs := []*islandSubscription{}
+	for sub := range hub.subscriptions {
+		if !sub.closed && sub.dependsOn(resourceKeys) {
+			sub.running.Add(1)
+			subs = append(subs, sub)
+		}
+	}
+	hub.mutex.Unlock()
+
+	for _, sub := range subs {
+		go sub.refresh(resourceKeys)
+	}
+}
+
+// Re-renders all the subscribed islands that depend on any of the given
+// resources and pushes them to the subscribed pages, on all the server
+// instances connected through the PubSub.
+func Publish(resourceKeys ...string) {
+	message, _ := json.Marshal(resourceKeys)
+	err := pubSub.Publish(resourceChangesTopic, message)
+	if err != nil && configuration.Current.DebugMode {
+		fmt.Printf("Error publishing resource changes: %s\n", err.Error())
+	}
+}
+
+func newClientID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Parses the `Last-Event-ID`, which is composed of the client ID
+// and the event sequence number
+func parseLastEventID(lastEventID string) (string, uint64, bool) {
+	idx := strings.LastIndex(lastEventID, "-")
+	if idx <= 0 {
+		return "", 0, false
+	}
+	seq, err := strconv.ParseUint(lastEventID[idx+1:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return lastEventID[:idx], seq, true
+}
+
+// Handles the Server-Sent Events subscriptions. The islands to subscribe
+// to are given in the `islands` query parameter (separated with `;`), and
+// the page URL in the `url` query parameter, or the `Referer` header.
+func subscribeHandler(hwContext hw.HardwireContext) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+
+		pageUrl := c.QueryParam("url")
+		if pageUrl == "" {
+			pageUrl = req.Header.Get("Referer")
+		}
+
+		route, queuedIslands, err := prepareSubscription(c, pageUrl, utils.ParseHeaderList(c.QueryParam("islands")))
+		if err != nil {
+			return c.String(err.Code, err.Data)
+		}
+
+		clientID := c.QueryParam("client")
+		var lastSeq uint64
+		lastEventID := req.Header.Get("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = c.QueryParam("lastEventId")
+		}
+		if id, seq, ok := parseLastEventID(lastEventID); ok {
+			clientID = id
+			lastSeq = seq
+		}
+		if clientID == "" {
+			clientID = newClientID()
+		}
+
+		resp := c.Response()
+		resp.Header().Set("Content-Type", "text/event-stream")
+		resp.Header().Set("Cache-Control", "no-store")
+		resp.Header().Set("Connection", "keep-alive")
+		resp.Header().Set("X-Accel-Buffering", "no")
+		resp.WriteHeader(http.StatusOK)
+
+		realtimeConf := configuration.Current.Realtime
+		writer := &sseWriter{
+			ctx:   c,
+			log:   islandHub.replayLog(clientID),
+			mutex: &sync.Mutex{},
+		}
+
+		_, writeErr := resp.Write([]byte(fmt.Sprintf("retry: %d\n\n", realtimeConf.ReconnectDelay.Milliseconds())))
+		if writeErr != nil {
+			return nil
+		}
+		writer.mutex.Lock()
+		for _, event := range writer.log.since(lastSeq) {
+			writer.send(event)
+		}
+		writer.mutex.Unlock()
+		resp.Flush()
+
+		denied := make(chan struct{})
+		var denyOnce sync.Once
+
+		sub := &islandSubscription{
+			actx: &ActionContext{
+				HwContext: hwContext,
+				Echo:      c,
+			},
+			route:     route,
+			islands:   queuedIslands,
+			writer:    writer,
+			morphSwap: c.QueryParam("morph") == "true",
+			onClose: func() {
+				writer.log.active--
+				writer.log.expiresAt = time.Now().Add(realtimeConf.ReplayTTL)
+			},
+			// the stream gets closed, the client reconnecting is refused
+			onDenied: func(err error) {
+				denyOnce.Do(func() { close(denied) })
+			},
+		}
+		islandHub.add(sub)
+		defer func() {
+			// no new refreshes start after the subscription is removed
+			islandHub.remove(sub)
+			writer.close()
+			sub.running.Wait()
+		}()
+
+		heartbeat := time.NewTicker(realtimeConf.HeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-req.Context().Done():
+				return nil
+			case <-denied:
+				return nil
+			case <-heartbeat.C:
+				err := writer.heartbeat()
+				if err != nil {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// Resolves the page the subscription is made from (returning its route)
+// and the islands to subscribe to. The islands are rendered with the same
+// pipeline as during actions, which reads the page route and URL from the
+// request headers, so those get set on the request of the given context.
+func prepareSubscription(c echo.Context, pageUrl string, islandIDs *Array[string]) (string, *Array[*QueuedIsland], *utils.RequestError) {
+	currentUrl, err := url.Parse(pageUrl)
+	if err != nil || pageUrl == "" {
+		return "", nil, &utils.RequestError{Code: http.StatusBadRequest, Data: "Bad Request"}
+	}
+
+	view := views.GetPageViewRegistry().GetView(currentUrl.Path)
+	if view.IsNil() {
+		return "", nil, &utils.RequestError{Code: http.StatusNotFound, Data: "Not found"}
+	}
+	route := view.Get().GetRoutePathname()
+
+	queuedIslands := queueIslands(islandIDs)
+	if queuedIslands.Length() == 0 {
+		return "", nil, &utils.RequestError{Code: http.StatusBadRequest, Data: "Bad Request"}
+	}
+	// islands of other pages would get rendered without
+	// the guards of their page
+	for qi := range queuedIslands.Iter() {
+		if !view.Get().ContainsFragment(qi.Fragment) {
+			return "", nil, &utils.RequestError{Code: http.StatusForbidden, Data: "Forbidden"}
+		}
+	}
+
+	err = checkSubscriptionGuards(c, route, queuedIslands)
+	if err != nil {
+		return "", nil, guardRequestError(err)
+	}
+
+	c.Request().Header.Set("Hardwire-Dynamic-Fragment-Request", route)
+	c.Request().Header.Set("Hx-Current-Url", pageUrl)
+
+	return route, queuedIslands, nil
+}
+
+// Checks the guards of the page route and of the resources the
+// islands depend on
+func checkSubscriptionGuards(c echo.Context, route string, islands *Array[*QueuedIsland]) error {
+	err := CheckRouteGuards(c, route)
+	if err != nil {
+		return err
+	}
+
+	for resKey := range requiredResources(islands).Iter() {
+		entry, found := ResourceReg.find(resKey)
+		if !found {
+			continue
+		}
+		err = CheckResourceGuards(c, route, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Subscriptions can't be redirected, any denial is reported with its
+// status code
+func guardRequestError(err error) *utils.RequestError {
+	var denial *GuardDenial
+	if errors.As(err, &denial) && denial.Location == "" {
+		return &utils.RequestError{Code: denial.Code, Data: denial.Message}
+	}
+	return &utils.RequestError{Code: http.StatusForbidden, Data: "Forbidden"}
+}
+
+// Creates the render queue entries for the islands of given IDs,
+// islands or fragments that cannot be found are skipped.
+func queueIslands(islandIDs *Array[string]) *Array[*QueuedIsland] {
+	dynFragments := views.GetDynamicFragmentViewRegistry()
+	queued := NewArray([]*QueuedIsland{})
+
+	for island := range views.GetIslands().Iter() {
+		if !Contains(islandIDs, island.ID) {
+			continue
+		}
+		fragment := dynFragments.GetFragmentById(island.FragmentID).Get()
+		if fragment == nil {
+			continue
+		}
+		queued.Push(&QueuedIsland{
+			Island:            island,
+			Fragment:          fragment,
+			RequiredResources: NewArray(fragment.ResourceKeys()),
+		})
+	}
+
+	return queued
+}
+
+func MountSubscriptionEndpoint(hwContext hw.HardwireContext, server *echo.Echo) error {
+	err := listenForResourceChanges()
+	if err != nil {
+		return err
+	}
+
+	endpointPath := configuration.Current.Realtime.SubscribeURL
+	if configuration.Current.DebugMode {
+		fmt.Printf("Adding island subscriptions endpoint: %s\n", endpointPath)
+	}
+	server.GET(endpointPath, subscribeHandler(hwContext))
+	return nil
+}
@@ -0,0 +1,123 @@
+package resourceprovider_test
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire"
+	"github.com/ncpa0/hardwire/configuration"
+	resources "github.com/ncpa0/hardwire/resources"
+	"github.com/ncpa0/hardwire/views"
+	"github.com/stretchr/testify/assert"
+)
+
+type liveTestResource struct{}
+
+func (r *liveTestResource) Get(c *resources.DynamicRequestContext) (interface{}, error) {
+	return []string{"a"}, nil
+}
+
+func TestSubscriptionClosedOnceGuardsDeny(t *testing.T) {
+	ass := assert.New(t)
+
+	dir := t.TempDir()
+	write := func(name string, content string) {
+		err := os.MkdirAll(path.Dir(path.Join(dir, name)), 0755)
+		if err == nil {
+			err = os.WriteFile(path.Join(dir, name), []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("live.html", `<html><body><div hx-get="/__dyn/livefrag"></div></body></html>`)
+	write("live.meta.json", `{"version":1,"isDynamic":false,"resources":[]}`)
+	write("__dyn/livefrag.template.html", `<dynamic-fragment id="live-todos">updated</dynamic-fragment>`)
+	write("__dyn/livefrag.meta.json", `{"version":1,"resourceName":"live-todos","hash":"livefrag"}`)
+	write("__islands/live-todos.meta.json",
+		`{"version":1,"id":"live-todos","fragmentID":"livefrag","resource":"live-todos","type":"basic"}`)
+
+	oldHtmlDir := configuration.Current.HtmlDir
+	configuration.Current.HtmlDir = dir
+	t.Cleanup(func() { configuration.Current.HtmlDir = oldHtmlDir })
+	if !ass.NoError(views.LoadBuiltViews(dir)) {
+		return
+	}
+
+	var denied atomic.Bool
+	resources.ResourceReg.Register("live-todos", &liveTestResource{}).
+		Guard(func(ctx *resources.GuardContext) error {
+			if denied.Load() {
+				return resources.Forbidden()
+			}
+			return nil
+		})
+
+	server := echo.New()
+	server.Logger.SetOutput(io.Discard)
+	if !ass.NoError(resources.MountSubscriptionEndpoint(hardwire.HardwireContext, server)) {
+		return
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	query := url.Values{
+		"islands": {"live-todos"},
+		"url":     {httpServer.URL + "/live"},
+	}
+	resp, err := http.Get(httpServer.URL + configuration.Current.Realtime.SubscribeURL + "?" + query.Encode())
+	if !ass.NoError(err) {
+		return
+	}
+	defer resp.Body.Close()
+	ass.Equal(http.StatusOK, resp.StatusCode)
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	// waits for the line with the given prefix, false once the stream ends
+	waitFor := func(prefix string) bool {
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					return false
+				}
+				if strings.HasPrefix(line, prefix) {
+					return true
+				}
+			case <-timeout:
+				t.Fatal("timed out waiting for the event stream")
+			}
+		}
+	}
+
+	ass.True(waitFor("retry:"))
+	// the subscription is added after the retry line is flushed
+	time.Sleep(50 * time.Millisecond)
+
+	resources.Publish("live-todos")
+	ass.True(waitFor("event: island"))
+
+	denied.Store(true)
+	resources.Publish("live-todos")
+	// reads up to the end of the stream
+	ass.False(waitFor("\x00"), "expected the stream to be closed")
+}
@@ -0,0 +1,86 @@
+package resourceprovider
+
+import (
+	"strconv"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/configuration"
+	"github.com/ncpa0/hardwire/utils"
+	. "github.com/ncpa0cpl/ezs"
+)
+
+const loadedItemsKey = "hardwire.loaded-items."
+
+// A single page of a list resource. List fragments render the `Items`,
+// and as long as there's a `NextCursor` the next page gets requested
+// once the end of the list is reached.
+type Page[T any] struct {
+	Items []T
+	// Cursor of the next page, empty if this is the last one
+	NextCursor string
+}
+
+// Implemented by resources returning a single page of a list
+type Paginated interface {
+	GetItems() interface{}
+	GetNextCursor() string
+}
+
+func (p Page[T]) GetItems() interface{} {
+	return p.Items
+}
+
+func (p Page[T]) GetNextCursor() string {
+	return p.NextCursor
+}
+
+// Returns the cursor of the requested page, empty if the
+// first page is requested
+func (ctx *DynamicRequestContext) Cursor() string {
+	return ctx.Echo.QueryParam("cursor")
+}
+
+// Returns the number of items requested per page, within the
+// configured maximum. When a list is re-rendered after an action, the
+// page includes all the items the client has already loaded.
+func (ctx *DynamicRequestContext) Limit() int {
+	conf := configuration.Current.Pagination
+
+	limit, err := strconv.Atoi(ctx.Echo.QueryParam("limit"))
+	if err != nil || limit <= 0 {
+		limit = conf.DefaultLimit
+	}
+	if loaded, ok := ctx.Echo.Get(loadedItemsKey + ctx.resourceName).(int); ok && loaded > limit {
+		limit = loaded
+	}
+	if limit > conf.MaxLimit {
+		return conf.MaxLimit
+	}
+	return limit
+}
+
+// Stores how many items the client shows in each of the updated lists
+// (as sent in the list order header), for each resource of the lists,
+// so that the re-rendered list doesn't lose the pages loaded after the
+// first one.
+func setLoadedListItems(c echo.Context, islands *Array[*QueuedIsland]) {
+	order := utils.ParseListOrderHeader(
+		c.Request().Header.Get("Hardwire-Dynamic-List-Order"),
+	)
+	if len(order) == 0 {
+		return
+	}
+
+	for qi := range islands.Iter() {
+		keys, ok := order[qi.Island.ID]
+		if !ok || qi.RequiredResources == nil {
+			continue
+		}
+		for resKey := range qi.RequiredResources.Iter() {
+			loaded, _ := c.Get(loadedItemsKey + resKey).(int)
+			if keys.Length() > loaded {
+				c.Set(loadedItemsKey+resKey, keys.Length())
+			}
+		}
+	}
+}
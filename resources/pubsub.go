@@ -0,0 +1,96 @@
+package resourceprovider
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Delivers messages to all the subscribers of a topic. The default
+// implementation only reaches the subscribers within the current process,
+// implementations backed by a message broker (e.g. Redis, NATS) can be
+// used to reach all the server instances.
+type PubSub interface {
+	Publish(topic string, message []byte) error
+	// Registers the handler for messages published to the topic, the
+	// returned function removes it.
+	Subscribe(topic string, handler func(message []byte)) (func(), error)
+}
+
+type memorySubscriber struct {
+	handler func(message []byte)
+}
+
+type MemoryPubSub struct {
+	mutex       *sync.RWMutex
+	subscribers map[string][]*memorySubscriber
+}
+
+func NewMemoryPubSub() *MemoryPubSub {
+	return &MemoryPubSub{
+		mutex:       &sync.RWMutex{},
+		subscribers: map[string][]*memorySubscriber{},
+	}
+}
+
+func (ps *MemoryPubSub) Publish(topic string, message []byte) error {
+	ps.mutex.RLock()
+	subscribers := ps.subscribers[topic]
+	ps.mutex.RUnlock()
+
+	for _, sub := range subscribers {
+		sub.handler(message)
+	}
+	return nil
+}
+
+func (ps *MemoryPubSub) Subscribe(topic string, handler func(message []byte)) (func(), error) {
+	sub := &memorySubscriber{handler: handler}
+
+	ps.mutex.Lock()
+	ps.subscribers[topic] = append(ps.subscribers[topic], sub)
+	ps.mutex.Unlock()
+
+	return func() {
+		ps.mutex.Lock()
+		defer ps.mutex.Unlock()
+		subs := ps.subscribers[topic]
+		for i, s := range subs {
+			if s == sub {
+				ps.subscribers[topic] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+	}, nil
+}
+
+const resourceChangesTopic = "hardwire:resource-changes"
+
+var pubSub PubSub = NewMemoryPubSub()
+var unsubscribePubSub func()
+
+// Replaces the PubSub used to broadcast resource changes, it must be
+// set before the server is started.
+func UsePubSub(ps PubSub) {
+	pubSub = ps
+}
+
+// Starts listening for the resource changes published by any of
+// the server instances.
+func listenForResourceChanges() error {
+	if unsubscribePubSub != nil {
+		unsubscribePubSub()
+	}
+
+	unsubscribe, err := pubSub.Subscribe(resourceChangesTopic, func(message []byte) {
+		var resourceKeys []string
+		if json.Unmarshal(message, &resourceKeys) == nil {
+			islandHub.publish(resourceKeys)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	unsubscribePubSub = unsubscribe
+	return nil
+}
@@ -0,0 +1,248 @@
+package resourceprovider
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/configuration"
+	"github.com/ncpa0/hardwire/utils"
+)
+
+// Keeps track of the requests made by the clients. Implementations backed
+// by a shared store (e.g. Redis) can be used to apply the limits across
+// all the server instances.
+type RateLimitStore interface {
+	// Takes a single request from the allowance of the given key, returns
+	// false along with the time after which the next request will be
+	// allowed if the allowance is used up.
+	Allow(key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+type tokenBucket struct {
+	tokens   float64
+	updated  time.Time
+	capacity float64
+	window   time.Duration
+}
+
+// Token bucket store, the allowance of each key is refilled continuously,
+// at the rate of `limit` requests per `window`.
+type MemoryRateLimitStore struct {
+	mutex     *sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		mutex:     &sync.Mutex{},
+		buckets:   map[string]*tokenBucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (store *MemoryRateLimitStore) Allow(key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	store.sweep(now)
+
+	rate := float64(limit) / float64(window)
+	bucket, ok := store.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{
+			tokens:   float64(limit),
+			updated:  now,
+			capacity: float64(limit),
+			window:   window,
+		}
+		store.buckets[key] = bucket
+	} else {
+		elapsed := now.Sub(bucket.updated)
+		bucket.tokens = math.Min(bucket.capacity, bucket.tokens+float64(elapsed)*rate)
+		bucket.updated = now
+	}
+
+	if bucket.tokens < 1 {
+		retryAfter := time.Duration((1 - bucket.tokens) / rate)
+		return false, retryAfter, nil
+	}
+
+	bucket.tokens--
+	return true, 0, nil
+}
+
+// Drops the buckets that got refilled completely, those are the same
+// as new ones
+func (store *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(store.lastSweep) < time.Minute {
+		return
+	}
+	store.lastSweep = now
+	for key, bucket := range store.buckets {
+		if now.Sub(bucket.updated) > bucket.window {
+			delete(store.buckets, key)
+		}
+	}
+}
+
+var rateLimitStore RateLimitStore = NewMemoryRateLimitStore()
+
+// Replaces the store used to track the requests of the clients, it must
+// be set before the server is started.
+func UseRateLimitStore(store RateLimitStore) {
+	rateLimitStore = store
+}
+
+type actionLimits struct {
+	requests      int
+	window        time.Duration
+	maxBodySize   int64
+	maxConcurrent int
+	running       chan struct{}
+}
+
+// Limits how many requests a single client can make to the action within
+// the window, in addition to the global limit.
+func (action *Action) RateLimit(requests int, window time.Duration) *Action {
+	action.limits.requests = requests
+	action.limits.window = window
+	return action
+}
+
+// Overrides the configured maximum request body size for the action.
+func (action *Action) MaxBodySize(bytes int64) *Action {
+	action.limits.maxBodySize = bytes
+	return action
+}
+
+// Limits how many times the action can be performed at the same time,
+// across all the clients.
+func (action *Action) MaxConcurrent(n int) *Action {
+	action.limits.maxConcurrent = n
+	action.limits.running = make(chan struct{}, n)
+	return action
+}
+
+var globalRunning chan struct{}
+var globalRunningOnce = &sync.Once{}
+
+func globalSemaphore() chan struct{} {
+	globalRunningOnce.Do(func() {
+		if configuration.Current.RateLimit.MaxConcurrent > 0 {
+			globalRunning = make(chan struct{}, configuration.Current.RateLimit.MaxConcurrent)
+		}
+	})
+	return globalRunning
+}
+
+func clientKey(c echo.Context) string {
+	keyFunc := configuration.Current.RateLimit.ClientKey
+	if keyFunc != nil {
+		return keyFunc(c)
+	}
+	// without an extractor echo would trust the X-Forwarded-For and
+	// X-Real-IP headers, which any client can set
+	if c.Echo().IPExtractor != nil {
+		return c.RealIP()
+	}
+	return echo.ExtractIPDirect()(c.Request())
+}
+
+// Checks the global and the action's request limits, and caps the size
+// of the request body. Returns the function releasing the concurrency
+// slots taken by the request, which must be called once the action is
+// finished.
+func (action *Action) applyLimits(c echo.Context) (func(), error) {
+	conf := configuration.Current.RateLimit
+	client := clientKey(c)
+
+	if conf.Requests > 0 {
+		allowed, retryAfter, err := rateLimitStore.Allow("global:"+client, conf.Requests, conf.Window)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, rateLimited(c, retryAfter)
+		}
+	}
+	if action.limits.requests > 0 {
+		key := fmt.Sprintf("action:%s/%s/%s:%s", action.Resource, action.Method, action.Name, client)
+		allowed, retryAfter, err := rateLimitStore.Allow(key, action.limits.requests, action.limits.window)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, rateLimited(c, retryAfter)
+		}
+	}
+
+	maxBodySize := conf.MaxBodySize
+	if action.limits.maxBodySize > 0 {
+		maxBodySize = action.limits.maxBodySize
+	}
+	if maxBodySize > 0 {
+		req := c.Request()
+		if req.ContentLength > maxBodySize {
+			return nil, payloadTooLarge(c)
+		}
+		req.Body = http.MaxBytesReader(c.Response(), req.Body, maxBodySize)
+	}
+
+	semaphores := []chan struct{}{}
+	for _, sem := range []chan struct{}{globalSemaphore(), action.limits.running} {
+		if sem == nil {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+			semaphores = append(semaphores, sem)
+		default:
+			for _, taken := range semaphores {
+				<-taken
+			}
+			return nil, rateLimited(c, time.Second)
+		}
+	}
+
+	return func() {
+		for _, sem := range semaphores {
+			<-sem
+		}
+	}, nil
+}
+
+// Triggers an event htmx clients can listen to, as htmx doesn't swap
+// error responses by default.
+func triggerLimitEvent(c echo.Context, event string, detail map[string]interface{}) {
+	utils.Htmx(c).Trigger(event, detail)
+}
+
+func rateLimited(c echo.Context, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	triggerLimitEvent(c, "hardwire:rate-limited", map[string]interface{}{
+		"retryAfter": seconds,
+	})
+	return echo.NewHTTPError(http.StatusTooManyRequests, "Too many requests, try again later")
+}
+
+func payloadTooLarge(c echo.Context) error {
+	triggerLimitEvent(c, "hardwire:payload-too-large", map[string]interface{}{})
+	return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Request body is too large")
+}
+
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
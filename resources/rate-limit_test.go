@@ -0,0 +1,46 @@
+package resourceprovider_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	resources "github.com/ncpa0/hardwire/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+type rateLimitTestBody struct{}
+
+func TestRateLimitIgnoresForwardedHeaders(t *testing.T) {
+	ass := assert.New(t)
+	disableCSRF(t)
+
+	entry := resources.ResourceReg.Register("rate-limited", &idempotencyTestResource{})
+	resources.RegisterPostAction(entry, "run", func(body *rateLimitTestBody, ctx *resources.ActionContext) error {
+		return nil
+	}).RateLimit(1, time.Minute)
+
+	server := echo.New()
+	server.Logger.SetOutput(io.Discard)
+	resources.MountActionEndpoints(nil, server)
+
+	send := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, resources.ActionEndpointPath("rate-limited", "run"), nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	ass.Equal(http.StatusNoContent, send("1.1.1.1"))
+	// a different forwarded address doesn't make it another client
+	ass.Equal(http.StatusTooManyRequests, send("2.2.2.2"))
+
+	// unless the server is configured to trust the header
+	server.IPExtractor = echo.ExtractIPFromXFFHeader()
+	ass.Equal(http.StatusNoContent, send("3.3.3.3"))
+}
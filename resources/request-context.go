@@ -0,0 +1,85 @@
+package resourceprovider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// Returns the context of the request fetching the resource. It's canceled
+// once the client disconnects, the response is abandoned, or the timeout
+// of the resource passes, and should be passed along to any database
+// queries or requests made by the resource.
+func (ctx *DynamicRequestContext) Context() context.Context {
+	if ctx.reqCtx != nil {
+		return ctx.reqCtx
+	}
+	return ctx.Echo.Request().Context()
+}
+
+// Returns the context of the action request. It's canceled once the
+// client disconnects, writing the response fails, or the timeout of the
+// action passes.
+func (actx *ActionContext) Context() context.Context {
+	return actx.Echo.Request().Context()
+}
+
+// Limits how long fetching the resource can take, the context given to
+// the resource is canceled once it passes.
+func (entry *ResourceEntry) Timeout(timeout time.Duration) *ResourceEntry {
+	entry.timeout = timeout
+	return entry
+}
+
+// Limits how long the action, along with the rendering of the islands it
+// updates, can take. The context of the action is canceled once it passes.
+func (action *Action) Timeout(timeout time.Duration) *Action {
+	action.timeout = timeout
+	return action
+}
+
+func withResourceTimeout(entry *ResourceEntry, handler ResourceFunc) ResourceFunc {
+	if entry.timeout <= 0 {
+		return handler
+	}
+	return func(c *DynamicRequestContext) (interface{}, error) {
+		parent := c.Context()
+		timeoutCtx, cancel := context.WithTimeout(parent, entry.timeout)
+		defer cancel()
+
+		c.reqCtx = timeoutCtx
+		defer func() { c.reqCtx = parent }()
+
+		res, err := handler(c)
+		if err != nil && parent.Err() == nil && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+			c.Echo.Logger().Errorf("resource '%s' timed out: %s", entry.name, err.Error())
+			return nil, c.Err(http.StatusGatewayTimeout, "Resource request timed out")
+		}
+		return res, err
+	}
+}
+
+// Replaces the request context with one that can be canceled once the
+// response is abandoned, and which has the action's timeout applied.
+func (action *Action) withRequestContext(c echo.Context, actx *ActionContext) func() {
+	reqCtx, cancel := context.WithCancelCause(c.Request().Context())
+	cancelTimeout := func() {}
+	if action.timeout > 0 {
+		reqCtx, cancelTimeout = context.WithTimeout(reqCtx, action.timeout)
+	}
+
+	c.SetRequest(c.Request().WithContext(reqCtx))
+	actx.cancel = cancel
+
+	return func() {
+		cancelTimeout()
+		cancel(nil)
+	}
+}
+
+func isActionTimeout(c echo.Context, err error) bool {
+	return err != nil && errors.Is(c.Request().Context().Err(), context.DeadlineExceeded)
+}
@@ -0,0 +1,206 @@
+package resourceprovider
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/utils"
+)
+
+// Declares the resources this one depends on. They get resolved before
+// this resource is fetched, the ones independent of each other
+// concurrently, and their values are available through `GetDependency`.
+func (entry *ResourceEntry) DependsOn(resourceNames ...string) *ResourceEntry {
+	entry.dependencies = append(entry.dependencies, resourceNames...)
+	return entry
+}
+
+// Returns the resolved value of one of the resources the resource being
+// fetched depends on.
+func GetDependency[T interface{}](ctx *DynamicRequestContext, resourceName string) (T, error) {
+	var zero T
+	value, ok := ctx.dependencies[resourceName]
+	if !ok {
+		return zero, fmt.Errorf("resource '%s' is not a dependency of '%s'", resourceName, ctx.resourceName)
+	}
+	if value == nil {
+		return zero, nil
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf(
+			"dependency '%s' is of type %T, not %s",
+			resourceName, value, reflect.TypeOf((*T)(nil)).Elem(),
+		)
+	}
+	return typed, nil
+}
+
+type resolution struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// Keeps the resources fetched during a request, so that a resource
+// required by multiple islands, fragments or other resources is fetched
+// only once.
+type resolver struct {
+	mutex   *sync.Mutex
+	results map[string]*resolution
+}
+
+const resolverKey = "hardwire.resolver"
+
+var resolverMutex = &sync.Mutex{}
+
+func requestResolver(c echo.Context) *resolver {
+	resolverMutex.Lock()
+	defer resolverMutex.Unlock()
+
+	r, ok := c.Get(resolverKey).(*resolver)
+	if !ok {
+		r = &resolver{
+			mutex:   &sync.Mutex{},
+			results: map[string]*resolution{},
+		}
+		c.Set(resolverKey, r)
+	}
+	return r
+}
+
+// Drops the resources fetched so far within the request, e.g. once an
+// action changed them, so that they're fetched again.
+func resetResolver(c echo.Context) {
+	resolverMutex.Lock()
+	c.Set(resolverKey, nil)
+	resolverMutex.Unlock()
+}
+
+func (r *resolver) resolve(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	r.mutex.Lock()
+	if res, ok := r.results[key]; ok {
+		r.mutex.Unlock()
+		<-res.done
+		return res.value, res.err
+	}
+	res := &resolution{done: make(chan struct{})}
+	r.results[key] = res
+	r.mutex.Unlock()
+
+	res.value, res.err = fetch()
+	close(res.done)
+	return res.value, res.err
+}
+
+func resolutionKey(resourceName string, c *DynamicRequestContext) string {
+	params := make([]string, 0, len(c.params))
+	for key, value := range c.params {
+		params = append(params, key+"="+value)
+	}
+	sort.Strings(params)
+	return resourceName + "|" + c.routePathname + "|" + strings.Join(params, "&")
+}
+
+// Resolves all the dependencies of the resource concurrently, each of them
+// having its own dependencies resolved first.
+func resolveDependencies(c *DynamicRequestContext, entry *ResourceEntry) error {
+	if len(entry.dependencies) == 0 {
+		return nil
+	}
+
+	mutex := &sync.Mutex{}
+	dependencies := make(map[string]interface{}, len(entry.dependencies))
+
+	_, errs := utils.InParallel(entry.dependencies, func(name string) (interface{}, error) {
+		depEntry, found := ResourceReg.find(name)
+		if !found {
+			return nil, fmt.Errorf("resource '%s' depends on '%s', which doesn't exist", entry.name, name)
+		}
+		err := CheckResourceGuards(c.Echo, c.routePathname, depEntry)
+		if err != nil {
+			return nil, err
+		}
+
+		depCtx := NewDynamicRequestContext(c.Echo, c.params, c.routePathname)
+		depCtx.reqCtx = c.reqCtx
+		value, err := GetResourceHandler(depEntry)(depCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		mutex.Lock()
+		dependencies[name] = value
+		mutex.Unlock()
+		return nil, nil
+	})
+	if len(errs) > 0 {
+		// errors like guard denials or redirects need to reach the
+		// response as they are
+		return errs[0]
+	}
+
+	c.dependencies = dependencies
+	return nil
+}
+
+// Checks that all the resource dependencies are registered, and that
+// there are no dependency cycles.
+func CheckResourceDependencies() error {
+	errs := []error{}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+
+	var visit func(name string, path []string)
+	visit = func(name string, path []string) {
+		switch state[name] {
+		case visited:
+			return
+		case visiting:
+			cycleStart := 0
+			for i, p := range path {
+				if p == name {
+					cycleStart = i
+				}
+			}
+			cycle := append(append([]string{}, path[cycleStart:]...), name)
+			errs = append(errs, fmt.Errorf("resource dependency cycle: %s", strings.Join(cycle, " -> ")))
+			return
+		}
+
+		entry, found := ResourceReg.find(name)
+		if !found {
+			return
+		}
+
+		state[name] = visiting
+		for _, dep := range entry.dependencies {
+			if !HasResource(dep) {
+				errs = append(errs, fmt.Errorf("resource '%s' depends on '%s', which doesn't exist", name, dep))
+				continue
+			}
+			visit(dep, append(path, name))
+		}
+		state[name] = visited
+	}
+
+	names := ResourceReg.resources.Keys().ToSlice()
+	sort.Strings(names)
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name, []string{})
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package resourceprovider_test
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	resources "github.com/ncpa0/hardwire/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+type funcResource func(c *resources.DynamicRequestContext) (interface{}, error)
+
+func (f funcResource) Get(c *resources.DynamicRequestContext) (interface{}, error) {
+	return f(c)
+}
+
+func newDependencyTestContext() *resources.DynamicRequestContext {
+	server := echo.New()
+	server.Logger.SetOutput(io.Discard)
+	c := server.NewContext(httptest.NewRequest("GET", "/", nil), httptest.NewRecorder())
+	return resources.NewDynamicRequestContext(c, map[string]string{}, "/orders")
+}
+
+func TestResourceDependenciesAreResolvedOnce(t *testing.T) {
+	ass := assert.New(t)
+
+	var userFetches atomic.Int32
+	resources.ResourceReg.Register("deps-user", funcResource(func(c *resources.DynamicRequestContext) (interface{}, error) {
+		userFetches.Add(1)
+		return "alice", nil
+	}))
+	resources.ResourceReg.Register("deps-settings", funcResource(func(c *resources.DynamicRequestContext) (interface{}, error) {
+		user, err := resources.GetDependency[string](c, "deps-user")
+		return user + ":dark", err
+	})).DependsOn("deps-user")
+	orders := resources.ResourceReg.Register("deps-orders", funcResource(func(c *resources.DynamicRequestContext) (interface{}, error) {
+		user, err := resources.GetDependency[string](c, "deps-user")
+		if err != nil {
+			return nil, err
+		}
+		settings, err := resources.GetDependency[string](c, "deps-settings")
+		if err != nil {
+			return nil, err
+		}
+		_, err = resources.GetDependency[string](c, "deps-missing")
+		ass.Error(err)
+		return user + "/" + settings, nil
+	})).DependsOn("deps-user", "deps-settings")
+
+	value, err := resources.GetResourceHandler(orders)(newDependencyTestContext())
+	ass.NoError(err)
+	ass.Equal("alice/alice:dark", value)
+	// shared by the resource and its other dependency
+	ass.Equal(int32(1), userFetches.Load())
+}
+
+func TestResourceTimeoutCoversDependencies(t *testing.T) {
+	ass := assert.New(t)
+
+	var deadlineSeen atomic.Bool
+	resources.ResourceReg.Register("timeout-slow-dep", funcResource(func(c *resources.DynamicRequestContext) (interface{}, error) {
+		_, hasDeadline := c.Context().Deadline()
+		deadlineSeen.Store(hasDeadline)
+		select {
+		case <-c.Context().Done():
+			return nil, c.Context().Err()
+		case <-time.After(time.Second):
+			return "late", nil
+		}
+	}))
+	entry := resources.ResourceReg.Register("timeout-parent", funcResource(func(c *resources.DynamicRequestContext) (interface{}, error) {
+		return resources.GetDependency[string](c, "timeout-slow-dep")
+	})).DependsOn("timeout-slow-dep").Timeout(20 * time.Millisecond)
+
+	start := time.Now()
+	_, err := resources.GetResourceHandler(entry)(newDependencyTestContext())
+	ass.Less(time.Since(start), 500*time.Millisecond)
+	ass.True(deadlineSeen.Load())
+
+	var reqErr *resources.ResourceRequestError
+	if ass.True(errors.As(err, &reqErr)) {
+		ass.Equal(504, reqErr.Code)
+	}
+}
@@ -0,0 +1,31 @@
+package resourceprovider
+
+type ResourceFunc func(c *DynamicRequestContext) (interface{}, error)
+
+// Wraps the fetching of resources, e.g. to add timing, logging or
+// retries. Middlewares are applied whenever a resource is fetched, for
+// page renders, fragment requests and island updates alike.
+type ResourceMiddleware func(next ResourceFunc) ResourceFunc
+
+var globalResourceMiddlewares = []ResourceMiddleware{}
+
+// Adds middlewares applied to all the resources. Global middlewares wrap
+// the ones registered on the resources, and are run in the order they
+// were added.
+func UseResourceMiddleware(middlewares ...ResourceMiddleware) {
+	globalResourceMiddlewares = append(globalResourceMiddlewares, middlewares...)
+}
+
+// Adds middlewares applied only to this resource, run in the order
+// they were added.
+func (entry *ResourceEntry) Use(middlewares ...ResourceMiddleware) *ResourceEntry {
+	entry.middlewares = append(entry.middlewares, middlewares...)
+	return entry
+}
+
+func applyResourceMiddlewares(handler ResourceFunc, middlewares []ResourceMiddleware) ResourceFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
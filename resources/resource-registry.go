@@ -1,14 +1,23 @@
 package resourceprovider
 
 import (
+	"reflect"
+	"time"
+
 	"github.com/ncpa0/hardwire/utils"
 	. "github.com/ncpa0cpl/ezs"
 )
 
 type ResourceEntry struct {
-	name     string
-	resource Resource[interface{}]
-	actions  Array[*Action]
+	name        string
+	resource    Resource[interface{}]
+	actions     Array[*Action]
+	guards      []Guard
+	middlewares []ResourceMiddleware
+	timeout     time.Duration
+	// only known for the resources registered with `RegisterResource`
+	valueType    reflect.Type
+	dependencies []string
 }
 
 func (entry *ResourceEntry) findAction(method string, name string) (bool, *Action) {
@@ -17,6 +26,12 @@ func (entry *ResourceEntry) findAction(method string, name string) (bool, *Actio
 	})
 }
 
+func (entry *ResourceEntry) pushAction(action *Action) *Action {
+	action.Resource = entry.name
+	entry.actions.Push(action)
+	return action
+}
+
 type ResourceRegistry struct {
 	resources *Map[string, *ResourceEntry]
 }
@@ -62,6 +77,26 @@ func GetResource(name string) (*ResourceEntry, bool) {
 	})
 }
 
+// Returns the function fetching the resource, wrapped in the global
+// and the resource's middlewares. Resources are fetched once per request,
+// after all of their dependencies.
 func GetResourceHandler(entry *ResourceEntry) func(c *DynamicRequestContext) (interface{}, error) {
-	return entry.resource.Get
+	handler := applyResourceMiddlewares(entry.resource.Get, entry.middlewares)
+	handler = applyResourceMiddlewares(handler, globalResourceMiddlewares)
+	// the timeout covers the dependencies too, they're
+	// given the context with the deadline
+	fetch := withResourceTimeout(entry, func(c *DynamicRequestContext) (interface{}, error) {
+		err := resolveDependencies(c, entry)
+		if err != nil {
+			return nil, err
+		}
+		return handler(c)
+	})
+
+	return func(c *DynamicRequestContext) (interface{}, error) {
+		return requestResolver(c.Echo).resolve(resolutionKey(entry.name, c), func() (interface{}, error) {
+			c.resourceName = entry.name
+			return fetch(c)
+		})
+	}
 }
@@ -0,0 +1,86 @@
+package resourceprovider
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Resource entry that keeps the type of the value provided by the resource
+type TypedResourceEntry[T interface{}] struct {
+	*ResourceEntry
+}
+
+// Anything the actions can be registered on, i.e. a `*ResourceEntry` or
+// a `*TypedResourceEntry[T]`.
+type ResourceEntryRef interface {
+	getEntry() *ResourceEntry
+}
+
+func (entry *ResourceEntry) getEntry() *ResourceEntry {
+	return entry
+}
+
+type typedResource[T interface{}] struct {
+	resource Resource[T]
+}
+
+func (r *typedResource[T]) Get(c *DynamicRequestContext) (interface{}, error) {
+	value, err := r.resource.Get(c)
+	if err != nil {
+		return nil, err
+	}
+	// a nil pointer wrapped in the interface wouldn't be equal to nil,
+	// and the fragment wouldn't respond with a 404
+	if isNilValue(value) {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
+// Registers the resource, keeping the type of the value it provides, so
+// that it can be fetched by the actions without type assertions.
+func RegisterResource[T interface{}](reg *ResourceRegistry, name string, resource Resource[T]) *TypedResourceEntry[T] {
+	entry := reg.Register(name, &typedResource[T]{resource: resource})
+	entry.valueType = reflect.TypeOf((*T)(nil)).Elem()
+	return &TypedResourceEntry[T]{ResourceEntry: entry}
+}
+
+// Returns the type of the value provided by the resource, nil for the
+// resources registered without one.
+func (entry *ResourceEntry) ValueType() reflect.Type {
+	return entry.valueType
+}
+
+// Fetches the resource for the page the action was made from
+func (entry *TypedResourceEntry[T]) Fetch(actx *ActionContext) (T, error) {
+	return GetActionResource[T](actx, entry.name)
+}
+
+// Fetches the resource of the given name for the page the action was made
+// from, returning an error if it's not of the type T.
+func GetActionResource[T interface{}](actx *ActionContext, name string) (T, error) {
+	var zero T
+	value, err := actx.HwContext.GetResource(actx.Echo, name)
+	if err != nil {
+		return zero, err
+	}
+	if value == nil {
+		return zero, nil
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("resource '%s' is of type %T, not %s", name, value, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return typed, nil
+}
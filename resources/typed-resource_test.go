@@ -0,0 +1,46 @@
+package resourceprovider_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	resources "github.com/ncpa0/hardwire/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+type todo struct {
+	Title string
+}
+
+type todoResource struct {
+	todo *todo
+}
+
+func (r *todoResource) Get(c *resources.DynamicRequestContext) (*todo, error) {
+	return r.todo, nil
+}
+
+func TestTypedResourceNilValue(t *testing.T) {
+	ass := assert.New(t)
+
+	missing := resources.RegisterResource[*todo](resources.ResourceReg, "typed-missing-todo", &todoResource{})
+	found := resources.RegisterResource[*todo](resources.ResourceReg, "typed-found-todo", &todoResource{todo: &todo{Title: "a"}})
+
+	get := func(entry *resources.TypedResourceEntry[*todo]) (interface{}, error) {
+		c := echo.New().NewContext(httptest.NewRequest("GET", "/", nil), httptest.NewRecorder())
+		return resources.GetResourceHandler(entry.ResourceEntry)(
+			resources.NewDynamicRequestContext(c, map[string]string{}, "/todo"),
+		)
+	}
+
+	// a nil pointer is returned as an untyped nil, so the
+	// fragment handlers respond with a 404
+	value, err := get(missing)
+	ass.NoError(err)
+	ass.True(value == nil)
+
+	value, err = get(found)
+	ass.NoError(err)
+	ass.Equal(&todo{Title: "a"}, value)
+}
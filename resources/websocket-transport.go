@@ -0,0 +1,338 @@
+package resourceprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/configuration"
+	hw "github.com/ncpa0/hardwire/hw-context"
+	. "github.com/ncpa0cpl/ezs"
+	"golang.org/x/net/websocket"
+)
+
+// A message sent by the client over the WebSocket connection, either an
+// action invocation, or (with the `subscribe` type) a subscription to
+// island updates
+type wsInvocation struct {
+	// Either `action` (default) or `subscribe`
+	Type string `json:"type"`
+	// Client assigned ID, included in all the messages sent in
+	// response to this invocation
+	ID       string `json:"id"`
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	// Optional, only needed when the resource has multiple actions
+	// of the same name
+	Method string `json:"method"`
+	// Either a JSON object, or a string containing url-encoded form data
+	Body json.RawMessage `json:"body"`
+	// Hardwire and htmx request headers, e.g. `Hardwire-Islands-Update`
+	Headers map[string]string `json:"headers"`
+	// IDs of the islands to subscribe to, and the URL of the page
+	// they're displayed on
+	Islands []string `json:"islands"`
+	Url     string   `json:"url"`
+}
+
+// A message sent by the server in response to an invocation. Each flushed
+// part of the action response is sent as a `chunk`, once the action is
+// finished a `done` message is sent with the response status and headers.
+type wsMessage struct {
+	ID      string            `json:"id"`
+	Type    string            `json:"type"`
+	Html    string            `json:"html,omitempty"`
+	Status  int               `json:"status,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+type wsConnection struct {
+	conn          *websocket.Conn
+	writeMutex    *sync.Mutex
+	subscriptions []*islandSubscription
+}
+
+func (wsc *wsConnection) send(msg *wsMessage) error {
+	wsc.writeMutex.Lock()
+	defer wsc.writeMutex.Unlock()
+	return websocket.JSON.Send(wsc.conn, msg)
+}
+
+// Response writer streaming each flushed chunk of the action response
+// over the WebSocket connection
+type wsResponseWriter struct {
+	conn   *wsConnection
+	id     string
+	header http.Header
+	status int
+	buff   bytes.Buffer
+}
+
+func (w *wsResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *wsResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *wsResponseWriter) Write(data []byte) (int, error) {
+	return w.buff.Write(data)
+}
+
+func (w *wsResponseWriter) Flush() {
+	if w.buff.Len() == 0 {
+		return
+	}
+	html := w.buff.String()
+	w.buff.Reset()
+	w.conn.send(&wsMessage{ID: w.id, Type: "chunk", Html: html})
+}
+
+func (w *wsResponseWriter) done() error {
+	// error responses are sent as the error of the invocation
+	errMsg := ""
+	if w.status >= http.StatusBadRequest {
+		errMsg = w.buff.String()
+		w.buff.Reset()
+	}
+	w.Flush()
+
+	headers := map[string]string{}
+	for key := range w.header {
+		if strings.HasPrefix(strings.ToLower(key), "hx-") || strings.HasPrefix(strings.ToLower(key), "hardwire-") {
+			headers[key] = w.header.Get(key)
+		}
+	}
+
+	return w.conn.send(&wsMessage{
+		ID:      w.id,
+		Type:    "done",
+		Status:  w.status,
+		Headers: headers,
+		Error:   errMsg,
+	})
+}
+
+// Sends the island updates of the subscriptions made over the connection
+type wsIslandWriter struct {
+	conn *wsConnection
+}
+
+func (w *wsIslandWriter) Write(islandID string, data []byte) error {
+	return w.conn.send(&wsMessage{Type: "island", Html: string(data)})
+}
+
+// Subscribes the connection to the updates of the given islands, made
+// whenever any of the resources they depend on is published.
+func (wsc *wsConnection) subscribe(hwContext hw.HardwireContext, server *echo.Echo, inv *wsInvocation) error {
+	req := wsc.conn.Request().Clone(wsc.conn.Request().Context())
+	ctx := server.NewContext(req, &wsResponseWriter{conn: wsc, header: http.Header{}})
+
+	route, islands, reqErr := prepareSubscription(ctx, inv.Url, NewArray(inv.Islands))
+	if reqErr != nil {
+		return wsc.send(&wsMessage{ID: inv.ID, Type: "done", Status: reqErr.Code, Error: reqErr.Data})
+	}
+
+	sub := &islandSubscription{
+		actx: &ActionContext{
+			HwContext: hwContext,
+			Echo:      ctx,
+		},
+		route:     route,
+		islands:   islands,
+		writer:    &wsIslandWriter{conn: wsc},
+		morphSwap: inv.Headers["Hardwire-Htmx-Morph"] == "true",
+	}
+	// only this subscription ends, the connection can still be used
+	sub.onDenied = func(err error) {
+		islandHub.remove(sub)
+		reqErr := guardRequestError(err)
+		wsc.send(&wsMessage{ID: inv.ID, Type: "unsubscribed", Status: reqErr.Code, Error: reqErr.Data})
+	}
+	islandHub.add(sub)
+	wsc.subscriptions = append(wsc.subscriptions, sub)
+
+	return wsc.send(&wsMessage{ID: inv.ID, Type: "done", Status: http.StatusOK})
+}
+
+func findActionEndpoint(inv *wsInvocation) (*Action, error) {
+	entry, found := ResourceReg.find(inv.Resource)
+	if !found {
+		return nil, fmt.Errorf("resource not found: %s", inv.Resource)
+	}
+
+	found, action := entry.actions.Find(func(action *Action, _ int) bool {
+		return action.Name == inv.Action &&
+			(inv.Method == "" || strings.EqualFold(action.Method, inv.Method))
+	})
+	if !found {
+		return nil, fmt.Errorf("action not found: %s/%s", inv.Resource, inv.Action)
+	}
+
+	return action, nil
+}
+
+// Builds the request equivalent to the invocation made over http,
+// carrying over the headers of the connection request (cookies,
+// authorization, etc.)
+func newInvocationRequest(ctx context.Context, connReq *http.Request, action *Action, inv *wsInvocation) (*http.Request, error) {
+	var body []byte
+	contentType := echo.MIMEApplicationJSON
+
+	var formData string
+	if len(inv.Body) > 0 && json.Unmarshal(inv.Body, &formData) == nil {
+		body = []byte(formData)
+		contentType = echo.MIMEApplicationForm
+	} else if len(inv.Body) > 0 && string(inv.Body) != "null" {
+		body = inv.Body
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, action.Method,
+		ActionEndpointPath(action.Resource, action.Name),
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range connReq.Header {
+		switch http.CanonicalHeaderKey(key) {
+		case "Upgrade", "Connection", "Sec-Websocket-Key", "Sec-Websocket-Version",
+			"Sec-Websocket-Extensions", "Sec-Websocket-Protocol":
+			continue
+		}
+		req.Header[key] = values
+	}
+	for key, value := range inv.Headers {
+		req.Header.Set(key, value)
+	}
+	if len(body) > 0 {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+	req.Header.Set("Hx-Request", "true")
+	req.RemoteAddr = connReq.RemoteAddr
+	req.Host = connReq.Host
+
+	return req, nil
+}
+
+// Runs the invocation through the server as if it was made over http, so
+// it goes through all the middlewares, the action endpoint and the error
+// handler of the server. The response is streamed over the connection.
+func (wsc *wsConnection) perform(server *echo.Echo, inv *wsInvocation) error {
+	action, err := findActionEndpoint(inv)
+	if err != nil {
+		return wsc.send(&wsMessage{ID: inv.ID, Type: "done", Status: http.StatusNotFound, Error: err.Error()})
+	}
+
+	req, err := newInvocationRequest(wsc.conn.Request().Context(), wsc.conn.Request(), action, inv)
+	if err != nil {
+		return wsc.send(&wsMessage{ID: inv.ID, Type: "done", Status: http.StatusBadRequest, Error: err.Error()})
+	}
+
+	writer := &wsResponseWriter{
+		conn:   wsc,
+		id:     inv.ID,
+		header: http.Header{},
+		status: http.StatusOK,
+	}
+	server.ServeHTTP(writer, req)
+
+	return writer.done()
+}
+
+// Verifies that the connection is made from a page of the same origin
+func wsHandshake(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	originUrl, err := url.Parse(origin)
+	if err != nil || originUrl.Host != req.Host {
+		return errors.New("cross-origin WebSocket connections are not allowed")
+	}
+	return nil
+}
+
+func websocketHandler(hwContext hw.HardwireContext, server *echo.Echo) echo.HandlerFunc {
+	wsServer := websocket.Server{
+		Handshake: wsHandshake,
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+
+			wsc := &wsConnection{
+				conn:       conn,
+				writeMutex: &sync.Mutex{},
+			}
+
+			// invocations are performed one at a time, in the order they were
+			// received, once the queue is full the connection isn't read from
+			// until the queue frees up
+			queue := make(chan *wsInvocation, configuration.Current.Realtime.WebSocketQueueSize)
+			finished := make(chan struct{})
+
+			go func() {
+				defer close(finished)
+				for inv := range queue {
+					var err error
+					if inv.Type == "subscribe" {
+						err = wsc.subscribe(hwContext, server, inv)
+					} else {
+						err = wsc.perform(server, inv)
+					}
+					if err != nil {
+						server.Logger.Error("error sending action response over websocket: ", err)
+					}
+				}
+			}()
+
+			for {
+				var inv wsInvocation
+				err := websocket.JSON.Receive(conn, &inv)
+				if err != nil {
+					var syntaxErr *json.SyntaxError
+					if errors.As(err, &syntaxErr) {
+						wsc.send(&wsMessage{Type: "error", Error: "malformed message"})
+						continue
+					}
+					break
+				}
+				queue <- &inv
+			}
+
+			close(queue)
+			<-finished
+
+			for _, sub := range wsc.subscriptions {
+				islandHub.remove(sub)
+			}
+		},
+	}
+
+	return func(c echo.Context) error {
+		wsServer.ServeHTTP(c.Response(), c.Request())
+		return nil
+	}
+}
+
+func MountWebSocketEndpoint(hwContext hw.HardwireContext, server *echo.Echo) {
+	if !configuration.Current.Realtime.WebSocket {
+		return
+	}
+
+	endpointPath := configuration.Current.Realtime.WebSocketURL
+	if configuration.Current.DebugMode {
+		fmt.Printf("Adding WebSocket endpoint: %s\n", endpointPath)
+	}
+	server.GET(endpointPath, websocketHandler(hwContext, server))
+}
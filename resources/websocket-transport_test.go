@@ -0,0 +1,88 @@
+package resourceprovider_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/configuration"
+	resources "github.com/ncpa0/hardwire/resources"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+type wsTestMessage struct {
+	ID      string            `json:"id"`
+	Type    string            `json:"type"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Error   string            `json:"error"`
+}
+
+func TestWebSocketActionsGoThroughMiddlewares(t *testing.T) {
+	ass := assert.New(t)
+	disableCSRF(t)
+	webSocket := configuration.Current.Realtime.WebSocket
+	configuration.Current.Realtime.WebSocket = true
+	t.Cleanup(func() { configuration.Current.Realtime.WebSocket = webSocket })
+
+	entry := resources.ResourceReg.Register("ws-middleware", &idempotencyTestResource{})
+	resources.RegisterPostAction(entry, "run", func(body *idempotencyTestBody, ctx *resources.ActionContext) error {
+		return nil
+	})
+
+	server := echo.New()
+	server.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
+			c.Response().Header().Set("Hardwire-Middleware", "true")
+			return next(c)
+		}
+	})
+	resources.MountActionEndpoints(nil, server)
+	resources.MountWebSocketEndpoint(nil, server)
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	invoke := func(headers map[string]string) wsTestMessage {
+		wsUrl := "ws" + strings.TrimPrefix(httpServer.URL, "http") + configuration.Current.Realtime.WebSocketURL
+		config, err := websocket.NewConfig(wsUrl, httpServer.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.Header = http.Header{"Authorization": []string{"token"}}
+		conn, err := websocket.DialConfig(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		err = websocket.JSON.Send(conn, map[string]interface{}{
+			"id": "1", "resource": "ws-middleware", "action": "run", "headers": headers,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msg wsTestMessage
+		err = websocket.JSON.Receive(conn, &msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	msg := invoke(nil)
+	ass.Equal("done", msg.Type)
+	ass.Equal(http.StatusNoContent, msg.Status)
+	ass.Equal("true", msg.Headers["Hardwire-Middleware"])
+
+	// the headers of the invocation override the ones of the connection
+	msg = invoke(map[string]string{"Authorization": ""})
+	ass.Equal(http.StatusUnauthorized, msg.Status)
+	ass.Contains(msg.Error, "Unauthorized")
+}
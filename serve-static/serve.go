@@ -250,15 +250,28 @@ func Serve(server *echo.Echo, baseUrl string, root string, conf *Configuration)
 	})
 }
 
-func sendFile(file *StaticFile, c echo.Context, conf *Configuration) error {
+func newStaticResponse(file *StaticFile) *StaticResponse {
 	sresp := &StaticResponse{
 		file:                     file,
 		cacheMaxAge:              86400,
 		cacheRequireRevalidation: false,
 		acceptRangeRequests:      true,
 		isPrivate:                false,
-		contentType:              file.ContentType,
 	}
+	if file != nil {
+		sresp.contentType = file.ContentType
+	}
+	return sresp
+}
+
+// Returns the Cache-Control header sent with the static files, unless
+// it's changed in the `BeforeSend` callback.
+func DefaultCacheControl() string {
+	return newStaticResponse(nil).buildCacheControlHeader()
+}
+
+func sendFile(file *StaticFile, c echo.Context, conf *Configuration) error {
+	sresp := newStaticResponse(file)
 
 	if conf.BeforeSend != nil {
 		err := conf.BeforeSend(sresp, c)
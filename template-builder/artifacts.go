@@ -0,0 +1,96 @@
+package templatebuilder
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/ncpa0/hardwire/configuration"
+)
+
+type ProjectPaths struct {
+	Entrypoint string
+	SrcDir     string
+	OutDir     string
+	StaticDir  string
+}
+
+// Resolves the paths from the current configuration against the
+// given working directory.
+func ResolvePaths(wd string) *ProjectPaths {
+	entrypoint := configuration.Current.Entrypoint
+	outDir := configuration.Current.HtmlDir
+	staticDir := configuration.Current.StaticDir
+
+	if !path.IsAbs(entrypoint) {
+		entrypoint = path.Join(wd, entrypoint)
+	}
+	if !path.IsAbs(outDir) {
+		outDir = path.Join(wd, outDir)
+	}
+	if !path.IsAbs(staticDir) {
+		staticDir = path.Join(wd, staticDir)
+	}
+
+	return &ProjectPaths{
+		Entrypoint: entrypoint,
+		SrcDir:     path.Dir(entrypoint),
+		OutDir:     outDir,
+		StaticDir:  staticDir,
+	}
+}
+
+// Runs the html generator with the current configuration and writes
+// the manifest of the produced artifact bundle. The generation is skipped
+// if neither the sources nor the builder configuration changed since the
+// previous build, and the previous artifacts are still intact.
+func BuildArtifacts(wd string) (*Manifest, error) {
+	paths := ResolvePaths(wd)
+
+	if configuration.Current.CleanBuild {
+		err := os.RemoveAll(paths.OutDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	previous, err := LoadManifest(paths.OutDir)
+	if err == nil && previous.ConfigHash == hashBuilderConfig() &&
+		verifyBundle(previous, paths.OutDir) == nil &&
+		verifyStatic(previous, paths.StaticDir) == nil {
+		sourceHash, err := HashSources(paths.SrcDir, paths.OutDir, paths.StaticDir)
+		if err != nil {
+			return nil, err
+		}
+
+		if sourceHash == previous.SourceHash {
+			if configuration.Current.DebugMode {
+				fmt.Print("Pages are up to date, skipping the build\n")
+			}
+			return previous, nil
+		}
+	}
+
+	report, err := BuildPages(
+		paths.Entrypoint,
+		paths.OutDir,
+		paths.StaticDir,
+		configuration.Current.StaticURL,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := WriteManifest(paths.OutDir, paths.SrcDir, paths.StaticDir, report.Assets)
+	if err != nil {
+		return nil, fmt.Errorf("error writing the build manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// Verifies the artifact bundle located in the configured html directory.
+func VerifyProjectArtifacts(wd string) error {
+	paths := ResolvePaths(wd)
+	return VerifyArtifacts(paths.OutDir, paths.SrcDir, paths.StaticDir)
+}
@@ -5,15 +5,17 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/ncpa0/hardwire/configuration"
 	"github.com/ncpa0/hardwire/utils"
 )
 
-func BuildPages(entrypoint string, outDir string, staticDir string, staticUrl string) error {
+// Runs the html generator and returns what it reported
+func BuildPages(entrypoint string, outDir string, staticDir string, staticUrl string) (*BuildReport, error) {
 	wd, err := os.Getwd()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !path.IsAbs(entrypoint) {
@@ -27,73 +29,67 @@ func BuildPages(entrypoint string, outDir string, staticDir string, staticUrl st
 	}
 
 	pagesDir := path.Dir(entrypoint)
+	builderConf := configuration.Current.Builder
 
-	initProject(pagesDir)
-
-	install := utils.Execute(("bun"), []string{
-		"a",
-		"hardwire-html-generator@0.0.1-beta.11", // Remember to update version after publish
-	}, &utils.ExecuteOptions{
-		Wd: pagesDir,
-	})
-
-	if install.Err != nil {
-		return fmt.Errorf("error installing html builder package:\n%s %s", install.Stdout, install.Stderr)
+	rt, err := getRuntime(builderConf.Runtime)
+	if err != nil {
+		return nil, err
 	}
 
-	installDev := utils.Execute(("bun"), []string{
-		"a",
-		"-D",
-		"@types/bun",
-	}, &utils.ExecuteOptions{
-		Wd: pagesDir,
-	})
+	err = initProject(rt, pagesDir)
+	if err != nil {
+		return nil, err
+	}
 
-	if installDev.Err != nil {
-		return fmt.Errorf("error installing html builder package:\n%s %s", install.Stdout, install.Stderr)
+	err = installDependencies(rt, pagesDir)
+	if err != nil {
+		return nil, err
 	}
 
-	builderInit := utils.Execute("bun", []string{
-		"x",
-		"hardwire-html-generator",
+	builderInit := execute(rt.run(pagesDir, []string{
 		"init",
 		"--dir", pagesDir,
-	}, &utils.ExecuteOptions{
+	}), &utils.ExecuteOptions{
 		Wd: pagesDir,
 	})
 
 	if builderInit.Err != nil {
-		return fmt.Errorf("error installing html builder package:\n%s %s", builderInit.Stdout, builderInit.Stderr)
+		return nil, fmt.Errorf("error installing html builder package:\n%s %s", builderInit.Stdout, builderInit.Stderr)
 	}
 
 	if configuration.Current.DebugMode {
 		fmt.Print("Building static HTML...\n")
 	}
 
-	result := utils.Execute("bun", []string{
-		"x",
-		"hardwire-html-generator",
+	env := map[string]string{
+		"NODE_ENV":  "production",
+		reporterEnv: reporterFormat,
+	}
+
+	result := execute(rt.run(pagesDir, []string{
 		"build",
 		"--src", entrypoint,
 		"--outdir", outDir,
 		"--staticdir", staticDir,
 		"--staticurl", staticUrl,
-	}, &utils.ExecuteOptions{
-		Wd: pagesDir,
-		Env: map[string]string{
-			"NODE_ENV": "production",
-		},
+	}), &utils.ExecuteOptions{
+		Wd:  pagesDir,
+		Env: env,
 	})
 
-	if result.Err != nil {
-		return fmt.Errorf("error building pages:\n%s %s", result.Stdout, result.Stderr)
+	report := ParseReport(result.Stdout + "\n" + result.Stderr)
+
+	if result.Err != nil || len(report.Errors()) > 0 {
+		return nil, &BuildError{Report: report}
 	}
 
 	if configuration.Current.DebugMode {
-		fmt.Printf("%s\n", result.Stdout)
+		report.Print(os.Stdout, true)
+	} else if len(report.Warnings()) > 0 {
+		report.Print(os.Stderr, false)
 	}
 
-	return nil
+	return report, nil
 }
 
 type PackageJson struct {
@@ -102,7 +98,64 @@ type PackageJson struct {
 	Main            string            `json:"main"`
 }
 
-func initProject(srcpath string) error {
+func execute(cmd []string, options *utils.ExecuteOptions) *utils.ExecuteResult {
+	return utils.Execute(cmd[0], cmd[1:], options)
+}
+
+// Installs the html generator and its dependencies, according to the
+// builder configuration.
+func installDependencies(rt *jsRuntime, pagesDir string) error {
+	builderConf := configuration.Current.Builder
+
+	if builderConf.Offline {
+		if configuration.Current.DebugMode {
+			fmt.Print("Offline mode, using the already installed html builder package\n")
+		}
+		return verifyInstalledGenerator(pagesDir)
+	}
+
+	if builderConf.FrozenLockfile {
+		if findLockfile(rt, pagesDir) == "" {
+			return fmt.Errorf(
+				"lockfile not found in %s, expected one of: %s",
+				pagesDir, strings.Join(rt.lockfiles, ", "),
+			)
+		}
+
+		install := execute(rt.install(true), &utils.ExecuteOptions{
+			Wd: pagesDir,
+		})
+		if install.Err != nil {
+			return fmt.Errorf("error installing dependencies from the lockfile:\n%s %s", install.Stdout, install.Stderr)
+		}
+
+		return verifyInstalledGenerator(pagesDir)
+	}
+
+	install := execute(rt.add([]string{
+		generatorPackage + "@" + ConfiguredGeneratorVersion(),
+	}, false), &utils.ExecuteOptions{
+		Wd: pagesDir,
+	})
+
+	if install.Err != nil {
+		return fmt.Errorf("error installing html builder package:\n%s %s", install.Stdout, install.Stderr)
+	}
+
+	if len(rt.devPackages) > 0 {
+		installDev := execute(rt.add(rt.devPackages, true), &utils.ExecuteOptions{
+			Wd: pagesDir,
+		})
+
+		if installDev.Err != nil {
+			return fmt.Errorf("error installing html builder package:\n%s %s", installDev.Stdout, installDev.Stderr)
+		}
+	}
+
+	return nil
+}
+
+func initProject(rt *jsRuntime, srcpath string) error {
 	if _, err := os.Stat(srcpath); os.IsNotExist(err) {
 		err := os.MkdirAll(srcpath, 0755)
 		if err != nil {
@@ -112,6 +165,14 @@ func initProject(srcpath string) error {
 
 	pkgJsonPath := path.Join(srcpath, "package.json")
 	if _, err := os.Stat(pkgJsonPath); os.IsNotExist(err) {
+		if configuration.Current.Builder.Offline {
+			return fmt.Errorf("the templates project in %s is not initialized, unable to initialize it in offline mode", srcpath)
+		}
+		// initializing the project would install whatever versions
+		// are the latest, not the ones from the lockfile
+		if configuration.Current.Builder.FrozenLockfile {
+			return fmt.Errorf("the templates project in %s is not initialized, unable to initialize it with a frozen lockfile", srcpath)
+		}
 
 		if configuration.Current.DebugMode {
 			fmt.Print("Initializing the templates project\n")
@@ -143,9 +204,9 @@ func initProject(srcpath string) error {
 			return err
 		}
 
-		install := utils.Execute(("bun"), []string{
-			"i",
-		}, nil)
+		install := execute(rt.install(false), &utils.ExecuteOptions{
+			Wd: srcpath,
+		})
 
 		if install.Err != nil {
 			return fmt.Errorf("error installing html builder package:\n%s %s", install.Stdout, install.Stderr)
@@ -0,0 +1,217 @@
+package templatebuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment variable instructing the generator to report in the
+// JSON lines format. Generators that don't support it ignore it and
+// their output is kept as is.
+const reporterEnv = "HARDWIRE_REPORTER"
+const reporterFormat = "jsonl"
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// An error or warning reported by the generator for a source file
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Message  string   `json:"message"`
+}
+
+// Returns the diagnostic position in the `file:line:column` format
+func (d *Diagnostic) Position() string {
+	if d.File == "" {
+		return ""
+	}
+	if d.Line == 0 {
+		return d.File
+	}
+	if d.Column == 0 {
+		return fmt.Sprintf("%s:%d", d.File, d.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+}
+
+func (d *Diagnostic) String() string {
+	pos := d.Position()
+	if pos == "" {
+		return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", pos, d.Severity, d.Message)
+}
+
+type Timing struct {
+	Phase    string
+	Duration time.Duration
+}
+
+// Everything reported by the generator during a single run
+type BuildReport struct {
+	Diagnostics []Diagnostic
+	Timings     []Timing
+	// Views emitted by the generator, relative to the output directory
+	Views []string
+	// Assets emitted by the generator, relative to the static directory
+	Assets []string
+	// Output lines that were not part of the reporting protocol
+	Output []string
+}
+
+type reportLine struct {
+	Type string `json:"type"`
+	Diagnostic
+	Phase      string  `json:"phase"`
+	DurationMs float64 `json:"durationMs"`
+	Path       string  `json:"path"`
+}
+
+// Parses the generator output. Each line is expected to be a JSON object
+// with a `type` of `diagnostic`, `timing`, `view` or `asset`, any other lines are
+// kept in the report `Output`.
+func ParseReport(output string) *BuildReport {
+	report := &BuildReport{}
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		var entry reportLine
+		if !strings.HasPrefix(trimmed, "{") || json.Unmarshal([]byte(trimmed), &entry) != nil {
+			report.Output = append(report.Output, line)
+			continue
+		}
+
+		switch entry.Type {
+		case "diagnostic":
+			if entry.Severity == "" {
+				entry.Severity = SeverityError
+			}
+			report.Diagnostics = append(report.Diagnostics, entry.Diagnostic)
+		case "timing":
+			report.Timings = append(report.Timings, Timing{
+				Phase:    entry.Phase,
+				Duration: time.Duration(entry.DurationMs * float64(time.Millisecond)),
+			})
+		case "view":
+			report.Views = append(report.Views, entry.Path)
+		case "asset":
+			report.Assets = append(report.Assets, entry.Path)
+		default:
+			report.Output = append(report.Output, line)
+		}
+	}
+
+	return report
+}
+
+func (r *BuildReport) filter(severity Severity) []Diagnostic {
+	result := []Diagnostic{}
+	for _, d := range r.Diagnostics {
+		if d.Severity == severity {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+func (r *BuildReport) Errors() []Diagnostic {
+	return r.filter(SeverityError)
+}
+
+func (r *BuildReport) Warnings() []Diagnostic {
+	return r.filter(SeverityWarning)
+}
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorDim    = "\033[2m"
+)
+
+// Prints the diagnostics, and when `verbose` is set also the timings,
+// emitted views and assets, and the raw output.
+func (r *BuildReport) Print(w io.Writer, verbose bool) {
+	color := isTerminal(w)
+	paint := func(c string, s string) string {
+		if !color {
+			return s
+		}
+		return c + s + colorReset
+	}
+
+	for _, d := range r.Diagnostics {
+		severityColor := colorRed
+		if d.Severity == SeverityWarning {
+			severityColor = colorYellow
+		}
+
+		pos := d.Position()
+		if pos != "" {
+			pos += ": "
+		}
+		fmt.Fprintf(w, "%s%s %s\n", pos, paint(severityColor, string(d.Severity)+":"), d.Message)
+	}
+
+	if !verbose {
+		return
+	}
+
+	for _, line := range r.Output {
+		fmt.Fprintln(w, line)
+	}
+	for _, view := range r.Views {
+		fmt.Fprintf(w, "%s %s\n", paint(colorDim, "emitted"), view)
+	}
+	for _, asset := range r.Assets {
+		fmt.Fprintf(w, "%s %s\n", paint(colorDim, "emitted"), asset)
+	}
+	for _, timing := range r.Timings {
+		fmt.Fprintf(w, "%s %s\n", paint(colorDim, timing.Phase+":"), timing.Duration)
+	}
+}
+
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Returned when the generator fails to build the pages
+type BuildError struct {
+	Report *BuildReport
+}
+
+func (e *BuildError) Error() string {
+	errs := e.Report.Errors()
+	if len(errs) == 0 {
+		return "error building pages:\n" + strings.Join(e.Report.Output, "\n")
+	}
+
+	lines := make([]string, len(errs))
+	for i, d := range errs {
+		lines[i] = d.String()
+	}
+	return "error building pages:\n" + strings.Join(lines, "\n")
+}
@@ -0,0 +1,35 @@
+package templatebuilder_test
+
+import (
+	"testing"
+	"time"
+
+	templatebuilder "github.com/ncpa0/hardwire/template-builder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReport(t *testing.T) {
+	ass := assert.New(t)
+
+	report := templatebuilder.ParseReport(`Building...
+{"type":"diagnostic","severity":"error","file":"src/home.tsx","line":12,"column":5,"message":"Unexpected token"}
+{"type":"diagnostic","severity":"warning","file":"src/about.tsx","line":3,"message":"Unused import"}
+{"type":"timing","phase":"render","durationMs":150}
+{"type":"view","path":"home.html"}
+{"type":"asset","path":"assets/css/main.css"}
+{"not":"protocol"}
+`)
+
+	ass.Len(report.Diagnostics, 2)
+	ass.Len(report.Errors(), 1)
+	ass.Len(report.Warnings(), 1)
+	ass.Equal("src/home.tsx:12:5: error: Unexpected token", report.Errors()[0].String())
+	ass.Equal("src/about.tsx:3", report.Warnings()[0].Position())
+	ass.Equal([]templatebuilder.Timing{{Phase: "render", Duration: 150 * time.Millisecond}}, report.Timings)
+	ass.Equal([]string{"home.html"}, report.Views)
+	ass.Equal([]string{"assets/css/main.css"}, report.Assets)
+	ass.Equal([]string{"Building...", `{"not":"protocol"}`}, report.Output)
+
+	err := &templatebuilder.BuildError{Report: report}
+	ass.Equal("error building pages:\nsrc/home.tsx:12:5: error: Unexpected token", err.Error())
+}
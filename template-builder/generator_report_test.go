@@ -0,0 +1,53 @@
+package templatebuilder_test
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	templatebuilder "github.com/ncpa0/hardwire/template-builder"
+	"github.com/stretchr/testify/assert"
+)
+
+// Runs the generator from this directory, the way the builder does
+func runGenerator(t *testing.T, src string) *templatebuilder.BuildReport {
+	outDir := t.TempDir()
+	cmd := exec.Command(
+		"bun", "./src/index.ts", "build",
+		"--src", src,
+		"--outdir", outDir,
+		"--staticdir", path.Join(outDir, "static"),
+		"--staticurl", "/static",
+	)
+	cmd.Env = append(os.Environ(), "NODE_ENV=production", "HARDWIRE_REPORTER=jsonl")
+	output, _ := cmd.CombinedOutput()
+	return templatebuilder.ParseReport(string(output))
+}
+
+func TestGeneratorReport(t *testing.T) {
+	if _, err := exec.LookPath("bun"); err != nil {
+		t.Skip("bun is not installed")
+	}
+	if _, err := os.Stat("node_modules"); err != nil {
+		t.Skip("the generator dependencies are not installed")
+	}
+	ass := assert.New(t)
+
+	report := runGenerator(t, "./test/pages/page.tsx")
+	ass.Empty(report.Errors())
+	ass.Contains(report.Views, "home.html")
+	ass.Contains(report.Views, "products/1.html")
+
+	phases := []string{}
+	for _, timing := range report.Timings {
+		phases = append(phases, timing.Phase)
+	}
+	ass.Equal([]string{"render", "write"}, phases)
+
+	report = runGenerator(t, "./test/pages/missing.tsx")
+	if ass.Len(report.Errors(), 1) {
+		ass.Contains(report.Errors()[0].Message, "missing.tsx")
+	}
+	ass.Empty(report.Views)
+}
@@ -0,0 +1,336 @@
+package templatebuilder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ncpa0/hardwire/configuration"
+	"github.com/ncpa0/hardwire/utils"
+)
+
+const ManifestFilename = "__manifest.json"
+const ManifestVersion = 3
+
+// Default version of the `hardwire-html-generator` package used to build the pages
+const GeneratorVersion = "0.0.1-beta.11" // Remember to update version after publish
+
+var ErrArtifactsMissing = errors.New("build artifacts are missing")
+var ErrArtifactsPartial = errors.New("build artifacts are incomplete")
+var ErrArtifactsStale = errors.New("build artifacts are stale")
+
+// Describes the artifact bundle produced by the template builder
+type Manifest struct {
+	ManifestVersion  int    `json:"manifestVersion"`
+	GeneratorVersion string `json:"generatorVersion"`
+	SourceHash       string `json:"sourceHash"`
+	// Hash of the builder options affecting the output
+	ConfigHash string    `json:"configHash"`
+	BuiltAt    time.Time `json:"builtAt"`
+	// Paths of all the source files, relative to the sources directory,
+	// mapped to the hash of their content
+	Sources map[string]string `json:"sources"`
+	// Paths of all the artifact files, relative to the output directory,
+	// mapped to the hash of their content
+	Files map[string]string `json:"files"`
+	// Paths of the files the generator emitted into the static directory
+	// (e.g. the css and js assets), relative to it, mapped to the hash of
+	// their content. Other files in the static directory are not tracked.
+	Static map[string]string `json:"static"`
+}
+
+// Directories inside the sources tree that are not considered part of
+// the sources when calculating the source hash
+var ignoredSourceDirs = []string{"node_modules", ".git"}
+
+// Creates a hash of all the files in the given directory tree. Directories
+// listed in `exclude` (e.g. the output directory, when it's located inside
+// the sources) are skipped.
+func HashSources(srcDir string, exclude ...string) (string, error) {
+	files, err := hashSourceFiles(srcDir, exclude...)
+	if err != nil {
+		return "", err
+	}
+	return hashFileMap(files), nil
+}
+
+func hashSourceFiles(srcDir string, exclude ...string) (map[string]string, error) {
+	files := map[string]string{}
+
+	err := utils.Walk(srcDir, func(root string, dirs []string, dirFiles []string) error {
+		rel := strings.TrimPrefix(root, srcDir)
+		for _, ignored := range ignoredSourceDirs {
+			if strings.Contains(rel+"/", "/"+ignored+"/") {
+				return nil
+			}
+		}
+		for _, excluded := range exclude {
+			if root == excluded || strings.HasPrefix(root, excluded+"/") {
+				return nil
+			}
+		}
+
+		for _, file := range dirFiles {
+			content, err := os.ReadFile(path.Join(root, file))
+			if err != nil {
+				return err
+			}
+			files[path.Join(rel, file)] = utils.HashBytes(content)
+		}
+
+		return nil
+	})
+
+	return files, err
+}
+
+func hashFileMap(files map[string]string) string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString(key)
+		sb.WriteString(":")
+		sb.WriteString(files[key])
+		sb.WriteString("\n")
+	}
+
+	return utils.Hash(sb.String())
+}
+
+func collectArtifacts(outDir string) (map[string]string, error) {
+	return collectFiles(outDir, ManifestFilename)
+}
+
+func collectFiles(dir string, exclude ...string) (map[string]string, error) {
+	files := map[string]string{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return files, nil
+	}
+
+	err := utils.Walk(dir, func(root string, dirs []string, dirFiles []string) error {
+		for _, file := range dirFiles {
+			fullPath := path.Join(root, file)
+			rel := strings.TrimPrefix(fullPath[len(dir):], "/")
+			if slices.Contains(exclude, rel) {
+				continue
+			}
+
+			content, err := os.ReadFile(fullPath)
+			if err != nil {
+				return err
+			}
+			files[rel] = utils.HashBytes(content)
+		}
+
+		return nil
+	})
+
+	return files, err
+}
+
+// Writes the manifest describing all the artifacts currently present
+// in the output directory and the given assets emitted into the static
+// directory.
+func WriteManifest(outDir string, srcDir string, staticDir string, assets []string) (*Manifest, error) {
+	sources, err := hashSourceFiles(srcDir, outDir, staticDir)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := collectArtifacts(outDir)
+	if err != nil {
+		return nil, err
+	}
+
+	static := map[string]string{}
+	for _, asset := range assets {
+		content, err := os.ReadFile(path.Join(staticDir, asset))
+		if err != nil {
+			return nil, err
+		}
+		static[asset] = utils.HashBytes(content)
+	}
+
+	manifest := &Manifest{
+		ManifestVersion:  ManifestVersion,
+		GeneratorVersion: ConfiguredGeneratorVersion(),
+		SourceHash:       hashFileMap(sources),
+		ConfigHash:       hashBuilderConfig(),
+		BuiltAt:          time.Now().UTC(),
+		Sources:          sources,
+		Files:            files,
+		Static:           static,
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(path.Join(outDir, ManifestFilename), content, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Creates a hash of the configuration options that affect the
+// generated output.
+func hashBuilderConfig() string {
+	return utils.Hash(strings.Join([]string{
+		ConfiguredGeneratorVersion(),
+		configuration.Current.Builder.Runtime,
+		path.Base(configuration.Current.Entrypoint),
+		configuration.Current.StaticDir,
+		configuration.Current.StaticURL,
+	}, "\n"))
+}
+
+func LoadManifest(outDir string) (*Manifest, error) {
+	content, err := os.ReadFile(path.Join(outDir, ManifestFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: no %s found in %s", ErrArtifactsMissing, ManifestFilename, outDir)
+		}
+		return nil, err
+	}
+
+	var manifest Manifest
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is corrupted: %s", ErrArtifactsPartial, ManifestFilename, err.Error())
+	}
+
+	return &manifest, nil
+}
+
+// Checks that the artifact bundle in the output directory is complete and
+// was produced from the current sources. The sources check is skipped
+// when the sources directory is not present (e.g. on production servers).
+func VerifyArtifacts(outDir string, srcDir string, staticDir string) error {
+	manifest, err := LoadManifest(outDir)
+	if err != nil {
+		return err
+	}
+
+	err = verifyBundle(manifest, outDir)
+	if err != nil {
+		return err
+	}
+
+	err = verifyStatic(manifest, staticDir)
+	if err != nil {
+		return err
+	}
+
+	if manifest.ConfigHash != hashBuilderConfig() {
+		return fmt.Errorf("%w: the builder configuration changed since the last build", ErrArtifactsStale)
+	}
+
+	if _, err := os.Stat(srcDir); err == nil {
+		sourceHash, err := HashSources(srcDir, outDir, staticDir)
+		if err != nil {
+			return err
+		}
+		if sourceHash != manifest.SourceHash {
+			return fmt.Errorf(
+				"%w: sources in %s changed since the last build",
+				ErrArtifactsStale, srcDir,
+			)
+		}
+	}
+
+	return nil
+}
+
+// Checks that all the artifacts listed in the manifest are present and
+// unchanged, and that there's nothing in the output directory that is
+// not listed in it.
+func verifyBundle(manifest *Manifest, outDir string) error {
+	if manifest.ManifestVersion != ManifestVersion {
+		return fmt.Errorf(
+			"%w: manifest version is %d, expected %d",
+			ErrArtifactsStale, manifest.ManifestVersion, ManifestVersion,
+		)
+	}
+
+	if manifest.GeneratorVersion != ConfiguredGeneratorVersion() {
+		return fmt.Errorf(
+			"%w: built with generator version %s, expected %s",
+			ErrArtifactsStale, manifest.GeneratorVersion, ConfiguredGeneratorVersion(),
+		)
+	}
+
+	if _, ok := manifest.Files["__actions.meta.json"]; !ok {
+		return fmt.Errorf("%w: __actions.meta.json is not part of the bundle", ErrArtifactsPartial)
+	}
+
+	for file, hash := range manifest.Files {
+		content, err := os.ReadFile(path.Join(outDir, file))
+		if err != nil {
+			return fmt.Errorf("%w: %s is missing", ErrArtifactsPartial, file)
+		}
+		if utils.HashBytes(content) != hash {
+			return fmt.Errorf("%w: %s was modified after the build", ErrArtifactsPartial, file)
+		}
+		if err := verifyMetafilePresent(manifest, file); err != nil {
+			return err
+		}
+	}
+
+	present, err := collectArtifacts(outDir)
+	if err != nil {
+		return err
+	}
+	for file := range present {
+		if _, ok := manifest.Files[file]; !ok {
+			return fmt.Errorf("%w: %s is not part of the bundle", ErrArtifactsStale, file)
+		}
+	}
+
+	return nil
+}
+
+// Checks that all the static files listed in the manifest are present and
+// unchanged. Other files can be added to the static directory, those are
+// not produced by the generator.
+func verifyStatic(manifest *Manifest, staticDir string) error {
+	for file, hash := range manifest.Static {
+		content, err := os.ReadFile(path.Join(staticDir, file))
+		if err != nil {
+			return fmt.Errorf("%w: static file %s is missing", ErrArtifactsPartial, file)
+		}
+		if utils.HashBytes(content) != hash {
+			return fmt.Errorf("%w: static file %s was modified after the build", ErrArtifactsPartial, file)
+		}
+	}
+	return nil
+}
+
+// Each of the html views must be accompanied by its metadata file
+func verifyMetafilePresent(manifest *Manifest, file string) error {
+	if path.Ext(file) != ".html" {
+		return nil
+	}
+
+	basename := strings.TrimSuffix(strings.TrimSuffix(file, ".html"), ".template")
+	metaFile := basename + ".meta.json"
+
+	if _, ok := manifest.Files[metaFile]; !ok {
+		return fmt.Errorf("%w: %s is missing its metadata file %s", ErrArtifactsPartial, file, metaFile)
+	}
+
+	return nil
+}
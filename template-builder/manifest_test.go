@@ -0,0 +1,60 @@
+package templatebuilder_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ncpa0/hardwire/configuration"
+	templatebuilder "github.com/ncpa0/hardwire/template-builder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyArtifacts(t *testing.T) {
+	ass := assert.New(t)
+	dir := t.TempDir()
+	srcDir := path.Join(dir, "src")
+	outDir := path.Join(dir, "views")
+	staticDir := path.Join(dir, "static")
+
+	write := func(file string, content string) {
+		err := os.MkdirAll(path.Dir(file), 0755)
+		if err == nil {
+			err = os.WriteFile(file, []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(path.Join(srcDir, "index.tsx"), "export default () => null")
+	write(path.Join(outDir, "__actions.meta.json"), `{"version":1,"registeredActions":[]}`)
+	write(path.Join(staticDir, "assets/css/main.css"), "body {}")
+	write(path.Join(staticDir, "favicon.ico"), "icon")
+
+	manifest, err := templatebuilder.WriteManifest(outDir, srcDir, staticDir, []string{"assets/css/main.css"})
+	if !ass.NoError(err) {
+		return
+	}
+	ass.Equal(templatebuilder.ManifestVersion, manifest.ManifestVersion)
+	ass.Contains(manifest.Static, "assets/css/main.css")
+	ass.NotContains(manifest.Static, "favicon.ico")
+	ass.NoError(templatebuilder.VerifyArtifacts(outDir, srcDir, staticDir))
+
+	// files not produced by the generator can be added
+	write(path.Join(staticDir, "logo.svg"), "<svg></svg>")
+	ass.NoError(templatebuilder.VerifyArtifacts(outDir, srcDir, staticDir))
+	// or changed
+	write(path.Join(staticDir, "favicon.ico"), "new icon")
+	ass.NoError(templatebuilder.VerifyArtifacts(outDir, srcDir, staticDir))
+
+	write(path.Join(staticDir, "assets/css/main.css"), "body { color: red }")
+	ass.ErrorIs(templatebuilder.VerifyArtifacts(outDir, srcDir, staticDir), templatebuilder.ErrArtifactsPartial)
+	os.Remove(path.Join(staticDir, "assets/css/main.css"))
+	ass.ErrorIs(templatebuilder.VerifyArtifacts(outDir, srcDir, staticDir), templatebuilder.ErrArtifactsPartial)
+	write(path.Join(staticDir, "assets/css/main.css"), "body {}")
+
+	staticDirConf := configuration.Current.StaticDir
+	defer func() { configuration.Current.StaticDir = staticDirConf }()
+	configuration.Current.StaticDir = "public"
+	ass.ErrorIs(templatebuilder.VerifyArtifacts(outDir, srcDir, staticDir), templatebuilder.ErrArtifactsStale)
+}
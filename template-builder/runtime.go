@@ -0,0 +1,176 @@
+package templatebuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/ncpa0/hardwire/configuration"
+)
+
+const generatorPackage = "hardwire-html-generator"
+
+// Describes how to install and run packages with one of the supported
+// JavaScript runtimes
+type jsRuntime struct {
+	name      string
+	lockfiles []string
+	// packages the runtime needs in the templates project
+	// besides the generator
+	devPackages []string
+	// command adding the given packages to the project
+	add func(packages []string, dev bool) []string
+	// command installing all the dependencies of the project
+	install func(frozen bool) []string
+	// command running the generator installed in the project
+	run func(pagesDir string, args []string) []string
+}
+
+var runtimes = map[string]*jsRuntime{
+	configuration.RuntimeBun: {
+		name:        configuration.RuntimeBun,
+		lockfiles:   []string{"bun.lock", "bun.lockb"},
+		devPackages: []string{"@types/bun"},
+		add: func(packages []string, dev bool) []string {
+			cmd := []string{"bun", "add"}
+			if dev {
+				cmd = append(cmd, "-D")
+			}
+			return append(cmd, packages...)
+		},
+		install: func(frozen bool) []string {
+			if frozen {
+				return []string{"bun", "install", "--frozen-lockfile"}
+			}
+			return []string{"bun", "install"}
+		},
+		run: func(pagesDir string, args []string) []string {
+			return append([]string{"bun", generatorBin(pagesDir)}, args...)
+		},
+	},
+	configuration.RuntimeNode: {
+		name:        configuration.RuntimeNode,
+		lockfiles:   []string{"package-lock.json"},
+		devPackages: []string{"tsx", "@types/node"},
+		add: func(packages []string, dev bool) []string {
+			cmd := []string{"npm", "install"}
+			if dev {
+				cmd = append(cmd, "--save-dev")
+			}
+			return append(cmd, packages...)
+		},
+		install: func(frozen bool) []string {
+			if frozen {
+				return []string{"npm", "ci"}
+			}
+			return []string{"npm", "install"}
+		},
+		run: func(pagesDir string, args []string) []string {
+			// node can't run the TypeScript (and jsx) sources by itself
+			return append([]string{"node", "--import", "tsx", generatorEntry(pagesDir)}, args...)
+		},
+	},
+	configuration.RuntimeDeno: {
+		name:      configuration.RuntimeDeno,
+		lockfiles: []string{"deno.lock"},
+		add: func(packages []string, dev bool) []string {
+			cmd := []string{"deno", "add"}
+			if dev {
+				cmd = append(cmd, "--dev")
+			}
+			for _, pkg := range packages {
+				cmd = append(cmd, "npm:"+pkg)
+			}
+			return cmd
+		},
+		install: func(frozen bool) []string {
+			if frozen {
+				return []string{"deno", "install", "--frozen"}
+			}
+			return []string{"deno", "install"}
+		},
+		run: func(pagesDir string, args []string) []string {
+			// the generator sources import each other without the extensions
+			return append([]string{
+				"deno", "run", "-A", "--unstable-sloppy-imports", generatorEntry(pagesDir),
+			}, args...)
+		},
+	},
+}
+
+func getRuntime(name string) (*jsRuntime, error) {
+	rt, ok := runtimes[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported builder runtime: '%s', expected one of: bun, node, deno", name)
+	}
+	return rt, nil
+}
+
+func generatorBin(pagesDir string) string {
+	return path.Join(pagesDir, "node_modules", ".bin", generatorPackage)
+}
+
+// Path of the generator sources, run directly by the runtimes that
+// don't use the bin script (which is run with bun)
+func generatorEntry(pagesDir string) string {
+	return path.Join(pagesDir, "node_modules", generatorPackage, "src", "index.ts")
+}
+
+// Returns the version of the html generator that should be used,
+// either the one given in the configuration or the default one.
+func ConfiguredGeneratorVersion() string {
+	if configuration.Current.Builder.GeneratorVersion != "" {
+		return configuration.Current.Builder.GeneratorVersion
+	}
+	return GeneratorVersion
+}
+
+func findLockfile(rt *jsRuntime, pagesDir string) string {
+	for _, lockfile := range rt.lockfiles {
+		lockfilePath := path.Join(pagesDir, lockfile)
+		if _, err := os.Stat(lockfilePath); err == nil {
+			return lockfilePath
+		}
+	}
+	return ""
+}
+
+// Reads the version of the html generator installed in the `node_modules`
+func installedGeneratorVersion(pagesDir string) (string, error) {
+	pkgJsonPath := path.Join(pagesDir, "node_modules", generatorPackage, "package.json")
+	content, err := os.ReadFile(pkgJsonPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%s is not installed in %s", generatorPackage, pagesDir)
+		}
+		return "", err
+	}
+
+	var pkgJson struct {
+		Version string `json:"version"`
+	}
+	err = json.Unmarshal(content, &pkgJson)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the installed %s package.json: %w", generatorPackage, err)
+	}
+
+	return pkgJson.Version, nil
+}
+
+func verifyInstalledGenerator(pagesDir string) error {
+	installed, err := installedGeneratorVersion(pagesDir)
+	if err != nil {
+		return err
+	}
+
+	expected := ConfiguredGeneratorVersion()
+	if installed != expected {
+		return fmt.Errorf(
+			"installed %s version (%s) doesn't match the expected version (%s)",
+			generatorPackage, installed, expected,
+		)
+	}
+
+	return nil
+}
@@ -13,7 +13,7 @@ type ParsedVars<T extends Record<string, Types>> = {
 type CmdEntry = {
   name: string;
   required: Record<string, Types>;
-  cb: (vars: any, argv: Argv) => void;
+  cb: (vars: any, argv: Argv) => void | Promise<void>;
   description?: string;
 };
 
@@ -32,7 +32,7 @@ export class Argv {
   }
 
   private printHelp() {
-    const out = Bun.stdout.writer();
+    const out = process.stdout;
 
     if (this.cmds.length) {
       out.write(`USAGE: ${this.scriptName} <command> [args]\n\n`);
@@ -59,13 +59,12 @@ export class Argv {
       out.write("\n");
     }
 
-    out.flush();
   }
 
   registerCommand<R extends Record<string, Types>>(
     name: string,
     required: R,
-    cb: (vars: ParsedVars<R>, argv: Argv) => void,
+    cb: (vars: ParsedVars<R>, argv: Argv) => void | Promise<void>,
   ) {
     const entry: CmdEntry = { name, required, cb };
     this.cmds.push(entry);
@@ -1,3 +1,4 @@
+import crypto from "node:crypto";
 import path from "node:path";
 import { collectRoutes } from "./collect-routes";
 import {
@@ -7,6 +8,7 @@ import {
   builderCtx,
 } from "./contexts";
 import { render } from "./renderer";
+import { reporter } from "./reporter";
 import { capitalize } from "./utils/capitalize";
 
 const PRETTY_HTML = process.env.PRETTY_HTML;
@@ -14,7 +16,7 @@ const PRETTY_HTML = process.env.PRETTY_HTML;
 const noop = () => {};
 
 function createHash(data: string) {
-  return Bun.hash.wyhash(data).toString(16);
+  return crypto.createHash("sha1").update(data).digest("hex").slice(0, 16);
 }
 
 type ExternalFile = {
@@ -42,7 +44,7 @@ export const buildPages = async (
   tree: JSX.Element,
   staticUrl: string,
 ) => {
-  console.log("Collecting routes...");
+  reporter.info("Collecting routes...");
 
   const routes = await collectRoutes(entrypointDir, tree, staticUrl);
 
@@ -117,12 +119,12 @@ export const buildPages = async (
     actions.push(action);
   };
 
-  console.log("Building pages...");
+  reporter.info("Building pages...");
 
   const pages: Array<Page> = [];
 
   for (const route of routes.getAll()) {
-    console.log(`Building page '${route.path}.html'`);
+    reporter.info(`Building page '${route.path}.html'`);
 
     const page: Page = {
       route: route.path,
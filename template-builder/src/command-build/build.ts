@@ -2,8 +2,17 @@ import { createElement } from "jsxte";
 import fs from "node:fs/promises";
 import path from "node:path";
 import { IslandMap } from "../components/island";
+import { reporter } from "../reporter";
+import { writeFile } from "../utils/write-file";
+
+/**
+ * Version of the metadata files format, must match the `MetadataVersion`
+ * of the hardwire runtime loading the files.
+ */
+const METADATA_VERSION = 1;
 
 type PageMetadata = {
+  version: number;
   isDynamic: boolean;
   resources?: {
     key: string;
@@ -12,10 +21,15 @@ type PageMetadata = {
 };
 
 type FragmentMetadata = {
+  version: number;
   resourceName: string;
   hash: string;
 };
 
+type BuildResult = Awaited<
+  ReturnType<typeof import("../build-pages").buildPages>
+>;
+
 function toJson(obj: any) {
   return JSON.stringify(obj, null, 2);
 }
@@ -39,14 +53,21 @@ export async function buildCmd(
 
   const App = mod.default;
 
-  const { pages, dynamicFragments, assets, actions } = await buildPages(
-    path.dirname(srcFile),
-    createElement(App),
-    staticurl,
+  const result = await reporter.time("render", () =>
+    buildPages(path.dirname(srcFile), createElement(App), staticurl),
   );
 
-  console.log("Saving results to filesystem...");
+  reporter.info("Saving results to filesystem...");
+  await reporter.time("write", () => writeResults(outDir, staticDir, result));
+
+  reporter.info("Done.");
+}
 
+async function writeResults(
+  outDir: string,
+  staticDir: string,
+  { pages, dynamicFragments, assets, actions }: BuildResult,
+) {
   await fs.mkdir(outDir, { recursive: true });
   await Promise.all([
     fs.mkdir(staticDir, { recursive: true }),
@@ -61,22 +82,26 @@ export async function buildCmd(
       const basedir = path.dirname(outfilePath);
       const meta: PageMetadata = {
         ...page.metadata,
+        version: METADATA_VERSION,
         isDynamic: page.dynamic != null,
         resources: page.dynamic?.resources,
       };
 
       await fs.mkdir(basedir, { recursive: true });
-      await Bun.write(outfilePath, page.html);
-      await Bun.write(metaFilePath, toJson(meta));
+      await writeFile(outfilePath, page.html);
+      await writeFile(metaFilePath, toJson(meta));
+      reporter.view(path.relative(outDir, outfilePath));
     }),
     ...assets.map(async (asset) => {
       const outfilePath = path.join(staticDir, asset.outFile);
       const basedir = path.dirname(outfilePath);
       await fs.mkdir(basedir, { recursive: true });
-      await Bun.write(outfilePath, asset.contents);
+      await writeFile(outfilePath, asset.contents);
+      reporter.asset(path.relative(staticDir, outfilePath));
     }),
     ...dynamicFragments.map(async (frag) => {
       const meta: FragmentMetadata = {
+        version: METADATA_VERSION,
         resourceName: frag.name,
         hash: frag.hash,
       };
@@ -85,20 +110,22 @@ export async function buildCmd(
         path.join(outDir, "__dyn", frag.hash) + ".template.html";
       const metaFile = path.join(outDir, "__dyn", frag.hash) + ".meta.json";
 
-      await Bun.write(outfilePath, frag.contents);
-      await Bun.write(metaFile, toJson(meta));
+      await writeFile(outfilePath, frag.contents);
+      await writeFile(metaFile, toJson(meta));
+      reporter.view(path.relative(outDir, outfilePath));
     }),
     ...Array.from(IslandMap.entries()).map((entry) => {
       const [, islandDef] = entry;
       const outfilePath =
         path.join(outDir, "__islands", islandDef.id) + ".meta.json";
-      return Bun.write(outfilePath, toJson(islandDef));
+      return writeFile(
+        outfilePath,
+        toJson({ ...islandDef, version: METADATA_VERSION }),
+      );
     }),
-    Bun.write(
+    writeFile(
       path.join(outDir, "__actions.meta.json"),
-      toJson({ registeredActions: actions }),
+      toJson({ version: METADATA_VERSION, registeredActions: actions }),
     ),
   ]);
-
-  console.log("Done.");
 }
@@ -18,6 +18,18 @@ const TSCONFIG_TEMPLATE = JSON.stringify(
   null,
   2,
 );
+// Deno doesn't read the tsconfig, the jsx options must be in its own config
+const DENO_CONFIG_TEMPLATE = JSON.stringify(
+  {
+    compilerOptions: {
+      jsx: "react-jsx",
+      jsxImportSource: "jsxte",
+    },
+    nodeModulesDir: "manual",
+  },
+  null,
+  2,
+);
 const CSS_TEMPLATE = ftmpl`
 body {
   margin: unset;
@@ -31,8 +43,8 @@ export default function App() {
   return (
     <Html nochunked>
       <Head>
-        <Style dirname={import.meta.dir} path="./style.css" />
-        <Script dirname={import.meta.dir} path="./index.client.ts" />
+        <Style dirname={import.meta.dirname} path="./style.css" />
+        <Script dirname={import.meta.dirname} path="./index.client.ts" />
       </Head>
       <div id="root">
         <nav>
@@ -53,26 +65,32 @@ export default function App() {
 export async function initCmd(wd: string) {
   const bunfigPath = path.join(wd, "bunfig.toml");
   if (!fs.existsSync(bunfigPath)) {
-    await Bun.write(bunfigPath, BUNFIG_TEMPLATE);
+    await fs.promises.writeFile(bunfigPath, BUNFIG_TEMPLATE);
+  }
+
+  // the jsx options are needed to run the generator with node (tsx)
+  const tsconfigPath = path.join(wd, "tsconfig.json");
+  if (!fs.existsSync(tsconfigPath)) {
+    await fs.promises.writeFile(tsconfigPath, TSCONFIG_TEMPLATE);
+  }
+
+  const denoConfigPath = path.join(wd, "deno.json");
+  if ("Deno" in globalThis && !fs.existsSync(denoConfigPath)) {
+    await fs.promises.writeFile(denoConfigPath, DENO_CONFIG_TEMPLATE);
   }
 
   const indexPath = path.join(wd, "index.tsx");
   if (!fs.existsSync(indexPath)) {
-    await Bun.write(indexPath, INDEX_TEMPLATE);
-
-    const tsconfigPath = path.join(wd, "tsconfig.json");
-    if (!fs.existsSync(tsconfigPath)) {
-      await Bun.write(tsconfigPath, TSCONFIG_TEMPLATE);
-    }
+    await fs.promises.writeFile(indexPath, INDEX_TEMPLATE);
 
     const stylePath = path.join(wd, "style.css");
     if (!fs.existsSync(stylePath)) {
-      await Bun.write(stylePath, CSS_TEMPLATE);
+      await fs.promises.writeFile(stylePath, CSS_TEMPLATE);
     }
 
     const indexClientPath = path.join(wd, "index.client.ts");
     if (!fs.existsSync(indexClientPath)) {
-      await Bun.write(indexClientPath, "");
+      await fs.promises.writeFile(indexClientPath, "");
     }
   }
 }
@@ -1,17 +1,51 @@
-export function SetupClientHelpers() {
+export type ClientHelpersOptions = {
+  /** Url of the island subscriptions endpoint, not subscribing when empty */
+  subscribeUrl?: string;
+};
+
+export function SetupClientHelpers(options: ClientHelpersOptions = {}) {
+  let subscription: EventSource | undefined;
+  let subscriptionKey = "";
+  let lastEventId = "";
+
   class Hardwire {
+    /**
+     * Lists the keys of the items the list island currently shows, so
+     * that the server can patch only the items that changed.
+     */
+    static listOrder(islandID: string, islandEl: HTMLElement) {
+      const keys: string[] = [];
+      const items = islandEl.querySelectorAll<HTMLElement>(
+        ".dynamic-list-element[data-item-key]",
+      );
+      for (let i = 0; i < items.length; i++) {
+        const item = items[i];
+        // items of the lists nested in this one
+        if (item.closest(".dynamic-list") !== islandEl) {
+          continue;
+        }
+        keys.push(encodeURIComponent(item.dataset.itemKey!));
+      }
+      return encodeURIComponent(islandID) + ":" + keys.join(",");
+    }
+
     static formHeaders(
       currentRouter: string,
       islands: string[],
       items: string[],
       morph?: boolean,
+      formID?: string,
     ) {
       let presentIslands = "";
+      let listOrder = "";
       for (let i = 0; i < islands.length; i++) {
         const islandID = islands[i];
         const islandEl = document.getElementById(islandID);
         if (islandEl) {
           presentIslands += ";" + islandID;
+          if (islandEl.classList.contains("dynamic-list")) {
+            listOrder += ";" + Hardwire.listOrder(islandID, islandEl);
+          }
         }
       }
 
@@ -25,9 +59,68 @@ export function SetupClientHelpers() {
         "Hardwire-Islands-Update": presentIslands.slice(1),
         "Hardwire-Dynamic-Fragment-Request": currentRouter,
         "Hardwire-Dynamic-List-Patch": listItems.slice(1),
+        "Hardwire-Dynamic-List-Order": listOrder.slice(1),
         "Hardwire-Htmx-Morph": morph ? "true" : "false",
+        "Hardwire-Form": formID ?? "",
       };
     }
+
+    /**
+     * Subscribes the page to the updates of the islands it shows, over
+     * Server-Sent Events. Re-subscribes whenever the shown islands or the
+     * page url change, continuing from the last received update.
+     */
+    static subscribe(subscribeUrl: string) {
+      const islands: string[] = [];
+      const islandEls = document.querySelectorAll<HTMLElement>(
+        "[data-hardwire-island]",
+      );
+      for (let i = 0; i < islandEls.length; i++) {
+        const islandID = islandEls[i]!.dataset.hardwireIsland!;
+        if (!islands.includes(islandID)) {
+          islands.push(islandID);
+        }
+      }
+
+      const key = islands.join(";") + "|" + location.href;
+      if (key === subscriptionKey) {
+        return;
+      }
+      subscriptionKey = key;
+      subscription?.close();
+      subscription = undefined;
+      if (islands.length === 0) {
+        return;
+      }
+
+      const query = new URLSearchParams({
+        islands: islands.join(";"),
+        url: location.href,
+      });
+      if (lastEventId) {
+        query.set("lastEventId", lastEventId);
+      }
+
+      const source = new EventSource(subscribeUrl + "?" + query.toString());
+      source.addEventListener("island", (e) => {
+        const event = e as MessageEvent<string>;
+        lastEventId = event.lastEventId;
+        // updates are out-of-band swaps of the islands
+        (window as any).htmx.swap(document.body, event.data, {
+          swapStyle: "none",
+        });
+      });
+      subscription = source;
+    }
+  }
+
+  const subscribeUrl = options.subscribeUrl;
+  if (subscribeUrl) {
+    // fired for the whole page once htmx is initialized, and for
+    // any content swapped in later
+    document.addEventListener("htmx:load", () => {
+      Hardwire.subscribe(subscribeUrl);
+    });
   }
 
   Object.defineProperty(window, "__hardwire", {
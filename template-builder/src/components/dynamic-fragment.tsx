@@ -80,6 +80,8 @@ export const DynamicFragment = async <T,>(
 
   return (
     <div
+      // islands are looked up by the client to subscribe to their updates
+      data-hardwire-island={"__island" in props ? props.id : undefined}
       hx-trigger={hxtrigger}
       hx-get={url}
       hx-swap={hxswap}
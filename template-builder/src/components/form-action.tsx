@@ -96,6 +96,7 @@ function registerAction(
     method: params.method,
     action: params.action,
     islandIDs: islands,
+    route: "/" + builder.currentRoute.join("/"),
   });
 }
 
@@ -121,6 +122,7 @@ type ActionParams = {
  *
  * <action.Form>
  *  <input name="Title" />
+ *  <action.FieldError name="Title" />
  *  <input name="Body" />
  *  <action.Submit>Submit</action.Submit>
  * </action.Form>
@@ -178,16 +180,17 @@ export const $action = (actionParams: ActionParams) => {
           islands.map((island) => IslandMap.get(island)?.id).filter(defined),
         ),
       );
-      if (islandsIDs.length > 0) {
-        const currentPath = bldr.currentRoute.join("/");
-        btnProps["hx-headers"] = `javascript: ...${Client.call(
-          "formHeaders",
-          currentPath,
-          islandsIDs,
-          (items ?? []).map(String),
-          morph ?? baseMorph,
-        )}`;
-      }
+      // the page route is always sent, the server checks the guards
+      // of the page the action is performed from
+      const currentPath = "/" + bldr.currentRoute.join("/");
+      btnProps["hx-headers"] = `javascript: ...${Client.call(
+        "formHeaders",
+        currentPath,
+        islandsIDs,
+        (items ?? []).map(String),
+        !!(morph ?? baseMorph),
+        uid,
+      )}`;
 
       registerAction(api, actionParams, islandsIDs);
 
@@ -249,19 +252,39 @@ export const $action = (actionParams: ActionParams) => {
         `/__resources/${resource}/actions/${actionName}`;
       btnProps["hx-swap"] = "none";
 
-      if (formCtx.islands.length > 0) {
-        const currentPath = bldr.currentRoute.join("/");
-        btnProps["hx-headers"] = `javascript: ...${Client.call(
-          "formHeaders",
-          currentPath,
-          formCtx.islands,
-          (formCtx.items ?? []).map(String),
-          morph ?? baseMorph,
-        )}`;
-      }
+      const currentPath = "/" + bldr.currentRoute.join("/");
+      btnProps["hx-headers"] = `javascript: ...${Client.call(
+        "formHeaders",
+        currentPath,
+        formCtx.islands,
+        (formCtx.items ?? []).map(String),
+        !!(morph ?? baseMorph),
+        uid,
+      )}`;
 
       return <button {...btnProps} />;
     },
+    /**
+     * Slot the binding and validation error of the given field is
+     * rendered into, when the submitted data is invalid. Without a
+     * `name`, errors not related to any field are rendered into it.
+     */
+    FieldError(
+      { name, ...props }: JSX.IntrinsicElements["span"] & { name?: string },
+      api: ComponentApi,
+    ) {
+      const formCtx = api.ctx.getOrFail(FormContext);
+      if (formCtx.formID !== uid) {
+        throw new Error(
+          "The field error must be a child of it's own form component.",
+        );
+      }
+
+      if (name === undefined) {
+        return <span {...props} data-form-error="" />;
+      }
+      return <span {...props} data-field-error={name} />;
+    },
   };
 
   return action;
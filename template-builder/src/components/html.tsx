@@ -156,7 +156,7 @@ export interface HtmxConfig {
 }
 
 export type HtmlProps = JSXTE.PropsWithChildren<{
-  headOptions?: Omit<InternalHeadProps, "extensions">;
+  headOptions?: Omit<InternalHeadProps, "extensions" | "subscribeUrl">;
   htmlProps?: JSX.IntrinsicElements["html"];
   /**
    * Don't include the idiomorph htmx extension.
@@ -170,6 +170,16 @@ export type HtmlProps = JSXTE.PropsWithChildren<{
    * Don't include the htmx-ext-head-support htmx extension.
    */
   nohead?: boolean;
+  /**
+   * Don't subscribe to the updates of the islands shown on the page.
+   */
+  nosubscribe?: boolean;
+  /**
+   * Url of the island subscriptions endpoint, must match the
+   * `Realtime.SubscribeURL` of the server.
+   * @default "/__hardwire/subscribe"
+   */
+  subscribeUrl?: string;
 }>;
 
 export const HtmlContext = defineContext<{
@@ -184,6 +194,8 @@ export async function Html(
     nochunked,
     nomorph,
     nohead,
+    nosubscribe,
+    subscribeUrl = "/__hardwire/subscribe",
   }: HtmlProps,
   api: ComponentApi,
 ) {
@@ -217,6 +229,7 @@ export async function Html(
       <InternalHead
         {...headProps}
         extensions={extensions}
+        subscribeUrl={nosubscribe === true ? undefined : subscribeUrl}
         htmxConfig={{ includeIndicatorStyles: false, ...headProps?.htmxConfig }}
       >
         {headContent}
@@ -234,6 +247,7 @@ type InternalHeadProps = {
    */
   htmxIntegrityHash?: string;
   extensions?: string[];
+  subscribeUrl?: string;
   htmxConfig: HtmxConfig;
 };
 
@@ -245,7 +259,7 @@ const InternalHead: JSXTE.Component<InternalHeadProps> = (
   const extFiles = componentApi.ctx.getOrFail(ExtFilesCtx);
 
   const hwScript = extFiles.register(
-    `(${String(SetupClientHelpers)})(${JSON.stringify(props.extensions ?? [])})`,
+    `(${String(SetupClientHelpers)})(${JSON.stringify({ subscribeUrl: props.subscribeUrl })})`,
     "hardwire",
     "js",
     { keepName: true },
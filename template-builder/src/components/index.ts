@@ -46,7 +46,7 @@ export const GLOBALS = {
 
 export const registerGlobalFunctions = () => {
   Object.defineProperties(
-    global,
+    globalThis,
     Object.fromEntries(
       Object.entries(GLOBALS).map(([k, v]) => {
         return [
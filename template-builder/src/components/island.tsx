@@ -1,25 +1,36 @@
 import { DynamicFragmentProps } from "./dynamic-fragment";
 
+/**
+ * Kind of the island, deciding how the server turns its rendered
+ * fragment into the update swapped on the client. Besides the built-in
+ * kinds, any kind registered on the server with `RegisterIslandKind`
+ * can be used.
+ */
+export type IslandType = "list" | "basic" | (string & {});
+
 export type IslandDefinition = {
   id: string;
   fragmentID: string;
   resource: string;
-  type: "list" | "basic";
+  type: IslandType;
 };
 
 export const IslandMap = new Map<JSXTE.Component<any>, IslandDefinition>();
 
 export function $island<T extends any, P extends object = {}>(
-  options: Omit<DynamicFragmentProps<T>, "render"> & { id: string },
+  options: Omit<DynamicFragmentProps<T>, "render"> & {
+    id: string;
+    type?: IslandType;
+  },
   Component: (props: P, data: AsProxy<T>) => JSX.Element,
 ): JSXTE.Component<P> {
-  const { id, ...dynamicFragmentProps } = options;
+  const { id, type = "basic", ...dynamicFragmentProps } = options;
 
   const islandEntry: IslandDefinition = {
     id,
     fragmentID: "",
     resource: dynamicFragmentProps.require,
-    type: "basic",
+    type,
   };
 
   const island: JSXTE.Component<P> = (props) => {
@@ -35,6 +46,8 @@ export function $island<T extends any, P extends object = {}>(
         __fragidgetter={(id: string) => {
           islandEntry.fragmentID = id;
         }}
+        // @ts-expect-error
+        __island
       />
     );
   };
@@ -1,5 +1,6 @@
 import { ComponentApi, defineContext } from "jsxte";
 import { StaticRoute } from "./route";
+import { reporter } from "../reporter";
 import { Switch } from "./router";
 
 export type TFunction = (
@@ -60,7 +61,7 @@ const tFunctionFactory = (translations: Record<string, any>): TFunction => {
       translation = translation[segment];
     }
     if (typeof translation !== "string") {
-      console.warn(`Translation not found for key: ${key}`);
+      reporter.diagnostic("warning", `Translation not found for key: ${key}`);
       return key;
     }
     if (params) {
@@ -1,9 +1,12 @@
-import { BuildConfig, escapeHTML } from "bun";
+import esbuild, { BuildOptions } from "esbuild";
 import { ComponentApi } from "jsxte";
 import fs from "node:fs/promises";
 import os from "node:os";
 import path from "node:path";
 import { builderCtx } from "../contexts";
+import { escapeHTML } from "../utils/escape-html";
+import { resolveModule } from "../utils/resolve-module";
+import { writeFile } from "../utils/write-file";
 
 const IS_PROD = process.env.NODE_ENV !== "development";
 
@@ -26,7 +29,7 @@ type ScriptPropsBase = {
    */
   type?: "module";
   onLoad?: (contents: string) => string | undefined;
-  buildOptions?: Partial<BuildConfig>;
+  buildOptions?: Partial<BuildOptions>;
 };
 
 export type PkgOpt = {
@@ -60,7 +63,7 @@ class Pkg {
   }
 
   async import(rootDir: string) {
-    const modulePath = await Bun.resolve(this.#name, rootDir);
+    const modulePath = resolveModule(this.#name, rootDir);
 
     if (this.#global) {
       return [
@@ -142,16 +145,19 @@ export const Script = async (
     );
   }
 
-  const config: BuildConfig = {
+  const config: BuildOptions & { entryPoints: string[] } = {
     minify: IS_PROD,
-    sourcemap: IS_PROD ? "none" : "inline",
+    sourcemap: IS_PROD ? false : "inline",
+    format: "esm",
     ...buildOptions,
-    entrypoints: [],
-    target: "browser",
+    entryPoints: [],
+    bundle: true,
+    write: false,
+    platform: "browser",
   };
 
   if (props.path) {
-    config.entrypoints = [path.join(props.dirname, props.path)];
+    config.entryPoints = [path.join(props.dirname, props.path)];
   } else if (pkgs) {
     let tmpFileContent = "";
 
@@ -169,34 +175,34 @@ export const Script = async (
       path.join(os.tmpdir(), "template-builder-js"),
     );
     const tmpFile = path.join(tmpdir, `${generateRandomName()}.ts`);
-    await Bun.write(tmpFile, tmpFileContent);
-    config.entrypoints = [tmpFile];
-    config.root = builder.entrypointDir;
+    await writeFile(tmpFile, tmpFileContent);
+    config.entryPoints = [tmpFile];
+    config.absWorkingDir = builder.entrypointDir;
   } else if (props.embed && props.children) {
     const tmpdir = await fs.mkdtemp(
       path.join(os.tmpdir(), "template-builder-js"),
     );
     const tmpFile = path.join(tmpdir, `${generateRandomName()}.ts`);
-    await Bun.write(
+    await writeFile(
       tmpFile,
       Array.isArray(props.children)
         ? props.children.map((n) => n.text).join("\n")
         : props.children.text,
     );
-    config.entrypoints = [tmpFile];
+    config.entryPoints = [tmpFile];
   }
 
-  if (config.entrypoints.length === 0) {
+  if (config.entryPoints.length === 0) {
     return <></>;
   }
 
-  const result = await Bun.build(config);
-
-  if (!result.success) {
-    throw new Error(`Build failed. [${config.entrypoints[0]}]`);
+  // esbuild throws with the errors of a failed build
+  const result = await esbuild.build(config);
+  if (!result.outputFiles?.length) {
+    throw new Error(`Build failed. [${config.entryPoints[0]}]`);
   }
 
-  let contents = await result.outputs[0].text().then((t) => t.trim());
+  let contents = result.outputFiles[0]!.text.trim();
   if (props.path) {
     contents = `/* ${props.path} */\n${contents}`;
   } else if (props.package) {
@@ -3,7 +3,8 @@ import esbuild from "esbuild";
 import type { ComponentApi } from "jsxte";
 import path from "node:path";
 import { builderCtx } from "../contexts";
-import { escapeHTML } from "bun";
+import { escapeHTML } from "../utils/escape-html";
+import { resolveModule } from "../utils/resolve-module";
 
 const IS_PROD = process.env.NODE_ENV !== "development";
 
@@ -93,8 +94,7 @@ export const Style = async (props: StyleProps, componentApi: ComponentApi) => {
     stylesheet = await bundleCss(filepath);
   } else if (props.package) {
     filepath = props.package!;
-    const modulePath = await Bun.resolve(props.package!, builder.entrypointDir);
-    // stylesheet = await Bun.file(modulePath).text();
+    const modulePath = resolveModule(props.package!, builder.entrypointDir);
     stylesheet = await bundleCss(modulePath);
   } else if (props.embed) {
     stylesheet = Array.isArray(props.children!)
@@ -107,7 +107,7 @@ export const Style = async (props: StyleProps, componentApi: ComponentApi) => {
   }
 
   const result = transform({
-    code: Buffer.from(stylesheet) as any,
+    code: new TextEncoder().encode(stylesheet) as any,
     filename: filepath,
     minify: IS_PROD,
     sourceMap: !IS_PROD,
@@ -5,6 +5,8 @@ export type ActionDefinition = {
   action: string;
   method: string;
   islandIDs: string[];
+  /** Route of the page the action is used on */
+  route: string;
 };
 
 export const builderCtx = defineContext<{
@@ -53,7 +55,7 @@ export const ExtFilesCtx = defineContext<{
   get(name: string): string;
 }>();
 
-Object.defineProperty(global, "ExtFilesCtx", {
+Object.defineProperty(globalThis, "ExtFilesCtx", {
   value: ExtFilesCtx,
   writable: false,
   enumerable: false,
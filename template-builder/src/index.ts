@@ -1,8 +1,8 @@
 #!/usr/bin/env bun
 
-/// <reference types="bun-types" />
 import path from "node:path";
 import { Argv } from "./argv";
+import { reporter } from "./reporter";
 
 declare global {
   namespace JSXTE {
@@ -49,10 +49,11 @@ async function main() {
     "Generates static HTML files from the given JSX component.",
   );
 
-  argv.run();
+  // awaited so that the errors of the commands get reported
+  await argv.run();
 }
 
 main().catch((err) => {
-  console.error(err);
+  reporter.error(err);
   process.exit(1);
 });
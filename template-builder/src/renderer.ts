@@ -1,10 +1,10 @@
-import { escapeHTML } from "bun";
 import {
   ComponentApi,
   ElementGenerator,
   JsxteRenderer,
   JsxteRenderError,
 } from "jsxte";
+import { escapeHTML } from "./utils/escape-html";
 
 const SELF_CLOSING_TAG_LIST = [
   "area",
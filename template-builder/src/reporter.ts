@@ -0,0 +1,128 @@
+/**
+ * Reports the progress of the generator. When the `HARDWIRE_REPORTER`
+ * env variable is set to `jsonl` (as done by the hardwire runtime),
+ * diagnostics, timings, emitted views and assets are printed as JSON lines,
+ * otherwise everything is printed as plain text.
+ */
+
+type Severity = "error" | "warning";
+
+type Location = {
+  file?: string;
+  line?: number;
+  column?: number;
+};
+
+const JSONL = process.env.HARDWIRE_REPORTER === "jsonl";
+
+function writeLine(entry: Record<string, any>) {
+  process.stdout.write(JSON.stringify(entry) + "\n");
+}
+
+function formatPosition(location?: Location) {
+  if (!location?.file) {
+    return "";
+  }
+  let pos = location.file;
+  if (location.line) {
+    pos += `:${location.line}`;
+    if (location.column) {
+      pos += `:${location.column}`;
+    }
+  }
+  return pos + ": ";
+}
+
+const STACK_FRAME = /\(?((?:file:\/\/)?\/[^():]+):(\d+):(\d+)\)?$/;
+
+/**
+ * Finds where the error was thrown from, either from the position
+ * given by Bun (e.g. for resolve errors) or from the first frame of
+ * the stack trace.
+ */
+function errorLocation(err: any): Location | undefined {
+  if (err?.position?.file) {
+    return {
+      file: err.position.file,
+      line: err.position.line,
+      column: err.position.column,
+    };
+  }
+  if (typeof err?.stack !== "string") {
+    return;
+  }
+  for (const line of err.stack.split("\n").slice(1)) {
+    const match = line.trim().match(STACK_FRAME);
+    if (match) {
+      return {
+        file: match[1]!.replace(/^file:\/\//, ""),
+        line: Number(match[2]),
+        column: Number(match[3]),
+      };
+    }
+  }
+}
+
+export const reporter = {
+  info(message: string) {
+    console.log(message);
+  },
+  diagnostic(severity: Severity, message: string, location?: Location) {
+    if (JSONL) {
+      writeLine({ type: "diagnostic", severity, message, ...location });
+      return;
+    }
+    const line = `${formatPosition(location)}${severity}: ${message}`;
+    if (severity === "error") {
+      console.error(line);
+    } else {
+      console.warn(line);
+    }
+  },
+  error(err: unknown) {
+    if (err instanceof AggregateError) {
+      for (const e of err.errors) {
+        reporter.error(e);
+      }
+      return;
+    }
+    // build failures of esbuild, with the location of each error
+    if (Array.isArray((err as any)?.errors)) {
+      for (const e of (err as any).errors) {
+        reporter.diagnostic("error", e.text, {
+          file: e.location?.file,
+          line: e.location?.line,
+          column: e.location?.column,
+        });
+      }
+      return;
+    }
+    const message = err instanceof Error ? err.message : String(err);
+    reporter.diagnostic("error", message, errorLocation(err));
+  },
+  timing(phase: string, durationMs: number) {
+    if (JSONL) {
+      writeLine({ type: "timing", phase, durationMs });
+    }
+  },
+  view(viewPath: string) {
+    if (JSONL) {
+      writeLine({ type: "view", path: viewPath });
+    }
+  },
+  /** Reports a file written to the static directory. */
+  asset(assetPath: string) {
+    if (JSONL) {
+      writeLine({ type: "asset", path: assetPath });
+    }
+  },
+  /** Runs the given phase of the build, reporting how long it took. */
+  async time<T>(phase: string, fn: () => Promise<T>): Promise<T> {
+    const start = performance.now();
+    try {
+      return await fn();
+    } finally {
+      reporter.timing(phase, performance.now() - start);
+    }
+  },
+};
@@ -0,0 +1,11 @@
+const ESCAPED: Record<string, string> = {
+  "&": "&amp;",
+  "<": "&lt;",
+  ">": "&gt;",
+  '"': "&quot;",
+  "'": "&#x27;",
+};
+
+export function escapeHTML(value: string): string {
+  return value.replace(/[&<>"']/g, (char) => ESCAPED[char]!);
+}
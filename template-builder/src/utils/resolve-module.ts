@@ -0,0 +1,11 @@
+import { createRequire } from "node:module";
+import path from "node:path";
+
+/**
+ * Resolves the path of the module as if it was imported from a file
+ * in the given directory.
+ */
+export function resolveModule(name: string, fromDir: string): string {
+  const require = createRequire(path.join(fromDir, "package.json"));
+  return require.resolve(name);
+}
@@ -0,0 +1,11 @@
+import fs from "node:fs/promises";
+import path from "node:path";
+
+/**
+ * Writes the file, creating the directories leading to it if they
+ * don't exist yet.
+ */
+export async function writeFile(filepath: string, contents: string) {
+  await fs.mkdir(path.dirname(filepath), { recursive: true });
+  await fs.writeFile(filepath, contents);
+}
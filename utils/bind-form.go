@@ -0,0 +1,325 @@
+package utils
+
+import (
+	"encoding"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// Binding or validation errors, keyed by the form field name
+type FieldErrors map[string]string
+
+func (errs FieldErrors) Error() string {
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	msgs := make([]string, len(fields))
+	for i, field := range fields {
+		msgs[i] = fmt.Sprintf("%s: %s", field, errs[field])
+	}
+	return "invalid form data: " + strings.Join(msgs, ", ")
+}
+
+func (errs FieldErrors) SendResponse(c echo.Context) error {
+	return c.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+		"errors": map[string]string(errs),
+	})
+}
+
+// Layouts accepted for `time.Time` fields, in the order they're tried,
+// covering the values sent by the html date and time inputs.
+var FormTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"15:04:05",
+	"15:04",
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	fileHeaderType      = reflect.TypeOf(&multipart.FileHeader{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Binds the form values and files onto the struct pointed to by `target`.
+//
+// Fields are matched by the `form` tag, or the field name if there's none
+// (`form:"-"` skips the field). Nested structs are bound from dotted names
+// (e.g. `address.city`), fields of embedded structs are bound as if they
+// were declared on the outer struct. Slices are filled from repeated keys.
+//
+// Values that cannot be converted to the field type are reported in the
+// returned `FieldErrors`, all the other fields are still bound.
+func BindForm(values url.Values, files map[string][]*multipart.FileHeader, target interface{}) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Pointer || targetValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("form can only be bound to a struct pointer, got %T", target)
+	}
+
+	b := &formBinder{
+		values: values,
+		files:  files,
+		errs:   FieldErrors{},
+	}
+	b.bindStruct(targetValue.Elem(), "")
+
+	if len(b.errs) > 0 {
+		return b.errs
+	}
+	return nil
+}
+
+type formBinder struct {
+	values url.Values
+	files  map[string][]*multipart.FileHeader
+	errs   FieldErrors
+}
+
+// Finds the values of the field, the key with the exact name is
+// preferred, otherwise the first one (in sorted order) matching
+// the name case-insensitively is used.
+func (b *formBinder) lookup(name string) ([]string, bool) {
+	if vals, ok := b.values[name]; ok {
+		return vals, true
+	}
+	match := ""
+	for key := range b.values {
+		if strings.EqualFold(key, name) && (match == "" || key < match) {
+			match = key
+		}
+	}
+	if match == "" {
+		return nil, false
+	}
+	return b.values[match], true
+}
+
+func (b *formBinder) hasPrefix(prefix string) bool {
+	for key := range b.values {
+		if len(key) > len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) {
+			return true
+		}
+	}
+	for key := range b.files {
+		if len(key) > len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *formBinder) bindStruct(structValue reflect.Value, prefix string) {
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		tag := field.Tag.Get("form")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && tag == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() != reflect.Struct {
+				continue
+			}
+			if fieldValue.Kind() == reflect.Pointer {
+				if !fieldValue.CanSet() {
+					continue
+				}
+				if fieldValue.IsNil() {
+					fieldValue.Set(reflect.New(fieldType))
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			b.bindStruct(fieldValue, prefix)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = field.Name
+		}
+		b.bindField(fieldValue, prefix+name)
+	}
+}
+
+func (b *formBinder) bindField(fieldValue reflect.Value, name string) {
+	fieldType := fieldValue.Type()
+
+	switch {
+	case fieldType == fileHeaderType:
+		if files := b.files[name]; len(files) > 0 {
+			fieldValue.Set(reflect.ValueOf(files[0]))
+		}
+		return
+	case fieldType.Kind() == reflect.Slice && fieldType.Elem() == fileHeaderType:
+		if files := b.files[name]; len(files) > 0 {
+			fieldValue.Set(reflect.ValueOf(files))
+		}
+		return
+	}
+
+	if fieldType.Kind() == reflect.Struct && fieldType != timeType && !reflect.PointerTo(fieldType).Implements(textUnmarshalerType) {
+		b.bindStruct(fieldValue, name+".")
+		return
+	}
+
+	if fieldType.Kind() == reflect.Pointer {
+		elemType := fieldType.Elem()
+		isNestedStruct := elemType.Kind() == reflect.Struct && elemType != timeType &&
+			!reflect.PointerTo(elemType).Implements(textUnmarshalerType)
+
+		if isNestedStruct {
+			if !b.hasPrefix(name + ".") {
+				return
+			}
+		} else if vals, ok := b.lookup(name); !ok || len(vals) == 0 || vals[0] == "" {
+			return
+		}
+
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.New(elemType))
+		}
+		b.bindField(fieldValue.Elem(), name)
+		return
+	}
+
+	vals, ok := b.lookup(name)
+	if !ok {
+		return
+	}
+
+	if fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(fieldType, 0, len(vals))
+		for _, val := range vals {
+			elem := reflect.New(fieldType.Elem()).Elem()
+			err := setFormValue(elem, val)
+			if err != nil {
+				b.errs[name] = err.Error()
+				return
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		fieldValue.Set(slice)
+		return
+	}
+
+	if len(vals) == 0 {
+		return
+	}
+	err := setFormValue(fieldValue, vals[0])
+	if err != nil {
+		b.errs[name] = err.Error()
+	}
+}
+
+// Converts the raw form value to the type of the given value, empty values
+// leave the value untouched, except for strings.
+func setFormValue(value reflect.Value, raw string) error {
+	if value.Kind() == reflect.String {
+		value.SetString(raw)
+		return nil
+	}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return setFormValue(value.Elem(), raw)
+	}
+
+	if value.Type() == timeType {
+		for _, layout := range FormTimeLayouts {
+			t, err := time.Parse(layout, raw)
+			if err == nil {
+				value.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("must be a valid date or time")
+	}
+
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshalerType) {
+		err := value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+		if err != nil {
+			return fmt.Errorf("is invalid: %s", err.Error())
+		}
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		switch strings.ToLower(raw) {
+		case "on", "yes":
+			value.SetBool(true)
+			return nil
+		case "off", "no":
+			value.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return fmt.Errorf("must be a valid duration")
+			}
+			value.SetInt(int64(d))
+			return nil
+		}
+		i, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a positive whole number")
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("cannot be bound to a field of type %s", value.Type().String())
+	}
+
+	return nil
+}
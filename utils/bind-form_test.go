@@ -0,0 +1,105 @@
+package utils_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ncpa0/hardwire/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type formAddress struct {
+	City string `form:"city"`
+	Zip  int    `form:"zip"`
+}
+
+type formMeta struct {
+	Source string `form:"source"`
+}
+
+type formBody struct {
+	formMeta
+	Name     string
+	Age      int          `form:"age"`
+	Score    float64      `form:"score"`
+	Active   bool         `form:"active"`
+	Born     time.Time    `form:"born"`
+	Nickname *string      `form:"nickname"`
+	Tags     []string     `form:"tag"`
+	Ids      []int        `form:"id"`
+	Address  formAddress  `form:"address"`
+	Billing  *formAddress `form:"billing"`
+	Ignored  string       `form:"-"`
+}
+
+func TestBindForm(t *testing.T) {
+	ass := assert.New(t)
+
+	var body formBody
+	err := utils.BindForm(url.Values{
+		"name":         {"John"},
+		"age":          {"42"},
+		"score":        {"4.5"},
+		"active":       {"on"},
+		"born":         {"1990-05-17"},
+		"nickname":     {"Johnny"},
+		"tag":          {"a", "b"},
+		"id":           {"1", "2", "3"},
+		"address.city": {"Berlin"},
+		"address.zip":  {"10115"},
+		"source":       {"web"},
+		"Ignored":      {"x"},
+	}, nil, &body)
+
+	ass.NoError(err)
+	ass.Equal("John", body.Name)
+	ass.Equal(42, body.Age)
+	ass.Equal(4.5, body.Score)
+	ass.True(body.Active)
+	ass.Equal(time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC), body.Born)
+	ass.Equal("Johnny", *body.Nickname)
+	ass.Equal([]string{"a", "b"}, body.Tags)
+	ass.Equal([]int{1, 2, 3}, body.Ids)
+	ass.Equal(formAddress{City: "Berlin", Zip: 10115}, body.Address)
+	ass.Nil(body.Billing)
+	ass.Equal("web", body.Source)
+	ass.Equal("", body.Ignored)
+
+	body = formBody{}
+	err = utils.BindForm(url.Values{
+		"age":         {"forty"},
+		"born":        {"yesterday"},
+		"billing.zip": {"abc"},
+		"name":        {"Jane"},
+	}, nil, &body)
+
+	fieldErrs, ok := err.(utils.FieldErrors)
+	if ass.True(ok) {
+		ass.Equal(utils.FieldErrors{
+			"age":         "must be a whole number",
+			"born":        "must be a valid date or time",
+			"billing.zip": "must be a whole number",
+		}, fieldErrs)
+	}
+	ass.Equal("Jane", body.Name)
+}
+
+func TestBindFormCaseInsensitiveLookup(t *testing.T) {
+	ass := assert.New(t)
+
+	values := url.Values{"NAME": {"upper"}, "name": {"lower"}, "Name": {"exact"}}
+	for i := 0; i < 20; i++ {
+		var body formBody
+		ass.NoError(utils.BindForm(values, nil, &body))
+		ass.Equal("exact", body.Name)
+	}
+
+	// without an exact match, the same key is picked every time
+	delete(values, "Name")
+	for i := 0; i < 20; i++ {
+		var body formBody
+		ass.NoError(utils.BindForm(values, nil, &body))
+		ass.Equal("upper", body.Name)
+	}
+}
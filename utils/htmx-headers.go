@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// Options of a `HX-Location` request, all but the path are optional.
+type HtmxLocation struct {
+	Path    string            `json:"path"`
+	Source  string            `json:"source,omitempty"`
+	Event   string            `json:"event,omitempty"`
+	Handler string            `json:"handler,omitempty"`
+	Target  string            `json:"target,omitempty"`
+	Swap    string            `json:"swap,omitempty"`
+	Select  string            `json:"select,omitempty"`
+	Values  map[string]string `json:"values,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Sets the htmx response headers. Headers can only be set before anything
+// is written to the response, e.g. before the first island update is
+// streamed.
+type HtmxHeaders struct {
+	c echo.Context
+}
+
+const htmxHeadersMutexKey = "hardwire.htmx-headers-mutex"
+
+// guards the creation of the per-request mutexes
+var htmxMutexesMutex = &sync.Mutex{}
+
+func Htmx(c echo.Context) *HtmxHeaders {
+	return &HtmxHeaders{c: c}
+}
+
+// Locks the response headers of the request, resources of a single
+// request can be fetched in parallel, all of them sharing the same
+// response headers. Anything writing the response while the headers
+// can still be set should hold the lock. Returns the unlock function.
+func LockResponseHeaders(c echo.Context) func() {
+	htmxMutexesMutex.Lock()
+	mutex, ok := c.Get(htmxHeadersMutexKey).(*sync.Mutex)
+	if !ok {
+		mutex = &sync.Mutex{}
+		c.Set(htmxHeadersMutexKey, mutex)
+	}
+	htmxMutexesMutex.Unlock()
+
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+func (h *HtmxHeaders) set(name string, value string) {
+	defer LockResponseHeaders(h.c)()
+
+	if h.c.Response().Committed {
+		h.c.Logger().Warnf("%s header set after the response was written, it will be ignored", name)
+		return
+	}
+	h.c.Response().Header().Set(name, value)
+}
+
+func (h *HtmxHeaders) trigger(header string, event string, detail interface{}) error {
+	defer LockResponseHeaders(h.c)()
+
+	if h.c.Response().Committed {
+		h.c.Logger().Warnf("%s header set after the response was written, it will be ignored", header)
+		return nil
+	}
+
+	headers := h.c.Response().Header()
+	merged, err := MergeTriggerHeader(headers.Get(header), event, detail)
+	if err != nil {
+		return err
+	}
+	headers.Set(header, merged)
+	return nil
+}
+
+// Triggers the event on the client as soon as the response is received,
+// with the given detail (which can be nil). All the events triggered
+// during a request are sent, if the same event is triggered more than
+// once, the last detail is used.
+func (h *HtmxHeaders) Trigger(event string, detail interface{}) error {
+	return h.trigger("HX-Trigger", event, detail)
+}
+
+// Triggers the event on the client after the swap step
+func (h *HtmxHeaders) TriggerAfterSwap(event string, detail interface{}) error {
+	return h.trigger("HX-Trigger-After-Swap", event, detail)
+}
+
+// Triggers the event on the client after the settle step
+func (h *HtmxHeaders) TriggerAfterSettle(event string, detail interface{}) error {
+	return h.trigger("HX-Trigger-After-Settle", event, detail)
+}
+
+// Overrides the `hx-swap` of the element making the request, e.g.
+// `outerHTML` or `none`.
+func (h *HtmxHeaders) Reswap(mode string) {
+	h.set("HX-Reswap", mode)
+}
+
+// Swaps the response into the element matching the selector, instead of
+// the `hx-target` of the element making the request.
+func (h *HtmxHeaders) Retarget(selector string) {
+	h.set("HX-Retarget", selector)
+}
+
+// Selects the part of the response that gets swapped, overriding the
+// `hx-select` of the element making the request.
+func (h *HtmxHeaders) Reselect(selector string) {
+	h.set("HX-Reselect", selector)
+}
+
+// Makes the client request the given path and swap it without a full
+// page reload, as if a `hx-boost`ed link was followed.
+func (h *HtmxHeaders) Location(path string) {
+	h.set("HX-Location", path)
+}
+
+func (h *HtmxHeaders) LocationWith(location HtmxLocation) error {
+	value, err := json.Marshal(location)
+	if err != nil {
+		return err
+	}
+	h.set("HX-Location", string(value))
+	return nil
+}
+
+// Pushes the url into the browser's history
+func (h *HtmxHeaders) PushUrl(url string) {
+	h.set("HX-Push-Url", url)
+}
+
+// Replaces the current url in the browser's location bar, without
+// creating a history entry.
+func (h *HtmxHeaders) ReplaceUrl(url string) {
+	h.set("HX-Replace-Url", url)
+}
+
+// Makes the client navigate to the url with a full page load
+func (h *HtmxHeaders) Redirect(url string) {
+	h.set("HX-Redirect", url)
+}
+
+// Makes the client reload the whole page
+func (h *HtmxHeaders) Refresh() {
+	h.set("HX-Refresh", "true")
+}
+
+// Adds the event to the value of a `HX-Trigger` header. The existing value
+// can be a JSON object or a comma separated list of event names, the
+// result is always a JSON object.
+func MergeTriggerHeader(existing string, event string, detail interface{}) (string, error) {
+	events := map[string]json.RawMessage{}
+
+	existing = strings.TrimSpace(existing)
+	if strings.HasPrefix(existing, "{") {
+		err := json.Unmarshal([]byte(existing), &events)
+		if err != nil {
+			return "", fmt.Errorf("invalid trigger header value: %w", err)
+		}
+	} else if existing != "" {
+		for _, name := range strings.Split(existing, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				events[name] = json.RawMessage("null")
+			}
+		}
+	}
+
+	detailJson, err := json.Marshal(detail)
+	if err != nil {
+		return "", fmt.Errorf("invalid detail of the '%s' event: %w", event, err)
+	}
+	events[event] = detailJson
+
+	result, err := json.Marshal(events)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
@@ -0,0 +1,62 @@
+package utils_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/ncpa0/hardwire/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeTriggerHeader(t *testing.T) {
+	ass := assert.New(t)
+
+	merge := func(existing string, event string, detail interface{}) string {
+		result, err := utils.MergeTriggerHeader(existing, event, detail)
+		ass.NoError(err)
+		return result
+	}
+
+	ass.Equal(`{"saved":null}`, merge("", "saved", nil))
+	ass.Equal(
+		`{"saved":null,"toast":{"level":"info","message":"Saved"}}`,
+		merge(`{"saved":null}`, "toast", map[string]string{"level": "info", "message": "Saved"}),
+	)
+	ass.Equal(`{"a":null,"b":null,"c":3}`, merge("a, b", "c", 3))
+	// the last detail of an event wins
+	ass.Equal(`{"toast":"second"}`, merge(`{"toast":"first"}`, "toast", "second"))
+
+	_, err := utils.MergeTriggerHeader(`{"broken"`, "toast", nil)
+	ass.Error(err)
+	_, err = utils.MergeTriggerHeader("", "toast", func() {})
+	ass.Error(err)
+}
+
+func TestHtmxTriggerConcurrently(t *testing.T) {
+	ass := assert.New(t)
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest("GET", "/", nil), rec)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ass.NoError(utils.Htmx(c).Trigger(fmt.Sprintf("event-%d", i), i))
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		ass.Contains(rec.Header().Get("HX-Trigger"), fmt.Sprintf(`"event-%d":%d`, i, i))
+	}
+
+	// once the response is written the headers are ignored
+	c.String(200, "")
+	utils.Htmx(c).Redirect("/")
+	ass.Equal("", rec.Header().Get("HX-Redirect"))
+}
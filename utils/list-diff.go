@@ -0,0 +1,143 @@
+package utils
+
+type ListOpKind string
+
+const (
+	ListOpDelete       ListOpKind = "delete"
+	ListOpReplace      ListOpKind = "replace"
+	ListOpInsertBefore ListOpKind = "insert-before"
+	ListOpInsertAfter  ListOpKind = "insert-after"
+	ListOpMoveBefore   ListOpKind = "move-before"
+	ListOpMoveAfter    ListOpKind = "move-after"
+	ListOpAppend       ListOpKind = "append"
+)
+
+// A single operation transforming the client list into the rendered one.
+// Insert and move operations are placed relative to the `Anchor` item,
+// which is always already in its final position when the operation is
+// applied. Append operations (made when the client has none of the items
+// to anchor to) have no anchor, the items go at the end of the list.
+type ListOp struct {
+	Kind   ListOpKind
+	Key    string
+	Anchor string
+}
+
+// Computes the operations needed to turn the list of `current` keys (as
+// present on the client) into the list of `next` keys, in order. Items
+// present in both lists are left in place, unless they're in `patched`,
+// in which case they get replaced. Only the minimal number of items is
+// moved (everything outside of the longest run of items that kept their
+// relative order).
+func DiffList(current []string, next []string, patched []string) []ListOp {
+	nextIdx := make(map[string]int, len(next))
+	for i, key := range next {
+		nextIdx[key] = i
+	}
+
+	ops := []ListOp{}
+	kept := []string{}
+	for _, key := range current {
+		if _, ok := nextIdx[key]; ok {
+			kept = append(kept, key)
+		} else {
+			ops = append(ops, ListOp{Kind: ListOpDelete, Key: key})
+		}
+	}
+
+	if len(kept) == 0 {
+		for _, key := range next {
+			ops = append(ops, ListOp{Kind: ListOpAppend, Key: key})
+		}
+		return ops
+	}
+
+	stable := longestIncreasingRun(kept, nextIdx)
+	currentSet := make(map[string]bool, len(kept))
+	for _, key := range kept {
+		currentSet[key] = true
+	}
+	patchedSet := make(map[string]bool, len(patched))
+	for _, key := range patched {
+		patchedSet[key] = true
+	}
+
+	firstStable := ""
+	for _, key := range next {
+		if stable[key] {
+			firstStable = key
+			break
+		}
+	}
+
+	placedAny := false
+	for i, key := range next {
+		if stable[key] {
+			placedAny = true
+			if patchedSet[key] {
+				ops = append(ops, ListOp{Kind: ListOpReplace, Key: key})
+			}
+			continue
+		}
+
+		op := ListOp{Key: key}
+		if !placedAny {
+			// everything before the first stable item is prepended to it
+			op.Anchor = firstStable
+			op.Kind = ListOpInsertBefore
+			if currentSet[key] {
+				op.Kind = ListOpMoveBefore
+			}
+		} else {
+			op.Anchor = next[i-1]
+			op.Kind = ListOpInsertAfter
+			if currentSet[key] {
+				op.Kind = ListOpMoveAfter
+			}
+		}
+		ops = append(ops, op)
+	}
+
+	return ops
+}
+
+// Returns the set of keys forming the longest subsequence of `keys`
+// that is already in the order given by `order`.
+func longestIncreasingRun(keys []string, order map[string]int) map[string]bool {
+	// tails[l] is the index (into keys) of the smallest tail of all the
+	// increasing subsequences of length l+1
+	tails := []int{}
+	prev := make([]int, len(keys))
+
+	for i, key := range keys {
+		pos := order[key]
+		lo, hi := 0, len(tails)
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if order[keys[tails[mid]]] < pos {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+		if lo > 0 {
+			prev[i] = tails[lo-1]
+		} else {
+			prev[i] = -1
+		}
+		if lo == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[lo] = i
+		}
+	}
+
+	result := make(map[string]bool, len(tails))
+	if len(tails) == 0 {
+		return result
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		result[keys[i]] = true
+	}
+	return result
+}
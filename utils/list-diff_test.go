@@ -0,0 +1,73 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/ncpa0/hardwire/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffList(t *testing.T) {
+	ass := assert.New(t)
+
+	diff := func(current, next, patched []string) []utils.ListOp {
+		return utils.DiffList(current, next, patched)
+	}
+
+	ass.Equal([]utils.ListOp{}, diff([]string{"a", "b"}, []string{"a", "b"}, nil))
+
+	ass.Equal(
+		[]utils.ListOp{
+			{Kind: utils.ListOpDelete, Key: "b"},
+			{Kind: utils.ListOpReplace, Key: "c"},
+		},
+		diff([]string{"a", "b", "c"}, []string{"a", "c"}, []string{"b", "c"}),
+	)
+
+	// prepend, insert in the middle and append
+	ass.Equal(
+		[]utils.ListOp{
+			{Kind: utils.ListOpInsertBefore, Key: "x", Anchor: "a"},
+			{Kind: utils.ListOpInsertAfter, Key: "y", Anchor: "a"},
+			{Kind: utils.ListOpInsertAfter, Key: "z", Anchor: "b"},
+		},
+		diff([]string{"a", "b"}, []string{"x", "a", "y", "b", "z"}, nil),
+	)
+
+	// only the item out of order is moved
+	ass.Equal(
+		[]utils.ListOp{
+			{Kind: utils.ListOpMoveBefore, Key: "d", Anchor: "a"},
+		},
+		diff([]string{"a", "b", "c", "d"}, []string{"d", "a", "b", "c"}, nil),
+	)
+	ass.Equal(
+		[]utils.ListOp{
+			{Kind: utils.ListOpMoveAfter, Key: "a", Anchor: "c"},
+		},
+		diff([]string{"a", "b", "c"}, []string{"b", "c", "a"}, nil),
+	)
+
+	ass.Equal(
+		[]utils.ListOp{
+			{Kind: utils.ListOpDelete, Key: "a"},
+		},
+		diff([]string{"a"}, []string{}, nil),
+	)
+
+	// nothing to anchor the items to
+	ass.Equal(
+		[]utils.ListOp{
+			{Kind: utils.ListOpAppend, Key: "a"},
+			{Kind: utils.ListOpAppend, Key: "b"},
+		},
+		diff([]string{}, []string{"a", "b"}, nil),
+	)
+	ass.Equal(
+		[]utils.ListOp{
+			{Kind: utils.ListOpDelete, Key: "a"},
+			{Kind: utils.ListOpAppend, Key: "b"},
+		},
+		diff([]string{"a"}, []string{"b"}, nil),
+	)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"net/url"
 	"strings"
 
 	. "github.com/ncpa0cpl/ezs"
@@ -13,3 +14,27 @@ func ParseHeaderList(raw string) *Array[string] {
 	})
 	return elems
 }
+
+// Parses the `Hardwire-Dynamic-List-Order` header, which lists the keys
+// of the items shown by each list island on the client, in order, e.g.
+// `todos:a,b,c;archive:` (island IDs and keys are URI encoded). Islands
+// not present in the header are not in the returned map.
+func ParseListOrderHeader(raw string) map[string]*Array[string] {
+	result := map[string]*Array[string]{}
+	for island := range ParseHeaderList(raw).Iter() {
+		islandID, keysList, _ := strings.Cut(island, ":")
+		islandID, err := url.PathUnescape(islandID)
+		if err != nil || islandID == "" {
+			continue
+		}
+		keys := NewArray([]string{})
+		for _, key := range strings.Split(keysList, ",") {
+			key, err := url.PathUnescape(key)
+			if err == nil && key != "" {
+				keys.Push(key)
+			}
+		}
+		result[islandID] = keys
+	}
+	return result
+}
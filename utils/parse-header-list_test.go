@@ -0,0 +1,21 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/ncpa0/hardwire/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseListOrderHeader(t *testing.T) {
+	ass := assert.New(t)
+
+	order := utils.ParseListOrderHeader("todos:a,b%2Cc;archive:;:x")
+	ass.Len(order, 2)
+	ass.Equal([]string{"a", "b,c"}, order["todos"].ToSlice())
+	ass.Equal([]string{}, order["archive"].ToSlice())
+	_, found := order["other"]
+	ass.False(found)
+
+	ass.Len(utils.ParseListOrderHeader(""), 0)
+}
@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 
 	echo "github.com/labstack/echo/v4"
 )
@@ -16,18 +18,38 @@ func (err *RequestError) Error() string {
 }
 
 func (err *RequestError) SendResponse(c echo.Context) error {
-	return c.String(err.Code, err.Data)
+	return RenderError(c, err.Code, err.Data)
 }
 
 type Sender interface {
 	SendResponse(c echo.Context) error
 }
 
+var errorRenderer func(c echo.Context, code int, message string) error
+
+// Replaces the plain text error responses, used to render the error views
+func UseErrorRenderer(renderer func(c echo.Context, code int, message string) error) {
+	errorRenderer = renderer
+}
+
+// Responds with the error view of the status code, or with plain text if
+// there's none.
+func RenderError(c echo.Context, code int, message string) error {
+	if errorRenderer != nil {
+		return errorRenderer(c, code, message)
+	}
+	return c.String(code, message)
+}
+
 func HandleError(c echo.Context, err error) error {
-	sender, ok := err.(Sender)
-	fmt.Println(err)
-	if ok {
+	var sender Sender
+	if errors.As(err, &sender) {
 		return sender.SendResponse(c)
 	}
-	return c.String(500, "Internal Server Error")
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return RenderError(c, httpErr.Code, fmt.Sprint(httpErr.Message))
+	}
+	c.Logger().Error(err)
+	return RenderError(c, http.StatusInternalServerError, "Internal Server Error")
 }
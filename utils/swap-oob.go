@@ -1,5 +1,10 @@
 package utils
 
+import (
+	"fmt"
+	"strings"
+)
+
 type OobSwap struct {
 	Extension string
 	Mode      string
@@ -14,6 +19,9 @@ func (o *OobSwap) Build() string {
 	// if o.Extension != "" {
 	// 	result += o.Extension + ":"
 	// }
+	if o.Mode == "true" {
+		return "true"
+	}
 	if o.Mode != "" {
 		result += o.Mode
 	} else {
@@ -24,3 +32,24 @@ func (o *OobSwap) Build() string {
 	}
 	return result
 }
+
+// Marks the html to be swapped out of band. OuterHTML swaps replace the
+// target with the oob element itself, so the attribute is added to the
+// root element of the html, other swaps insert the children of the oob
+// element, so the html gets wrapped.
+func (o *OobSwap) Apply(html string) (string, error) {
+	if o.Mode != "outerHTML" && o.Mode != "true" {
+		return fmt.Sprintf("<div hx-swap-oob=\"%s\">%s</div>", o.Build(), html), nil
+	}
+
+	html = strings.TrimSpace(html)
+	if !strings.HasPrefix(html, "<") || strings.HasPrefix(html, "</") || strings.HasPrefix(html, "<!") {
+		return "", fmt.Errorf("outerHTML swaps require the html to start with an element")
+	}
+	nameEnd := strings.IndexAny(html, " \t\n\r/>")
+	if nameEnd == -1 {
+		return "", fmt.Errorf("unclosed element tag")
+	}
+
+	return fmt.Sprintf("%s hx-swap-oob=\"%s\"%s", html[:nameEnd], o.Build(), html[nameEnd:]), nil
+}
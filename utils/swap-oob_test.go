@@ -0,0 +1,38 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/ncpa0/hardwire/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOobSwapApply(t *testing.T) {
+	ass := assert.New(t)
+
+	apply := func(swap utils.OobSwap, html string) string {
+		result, err := swap.Apply(html)
+		ass.NoError(err)
+		return result
+	}
+
+	ass.Equal(
+		`<div hx-swap-oob="beforeend:#todos"><li>a</li><li>b</li></div>`,
+		apply(utils.OobSwap{Mode: "beforeend", Selector: "#todos"}, "<li>a</li><li>b</li>"),
+	)
+	ass.Equal(
+		`<div hx-swap-oob="innerHtml:#panel">Saved</div>`,
+		apply(utils.OobSwap{Selector: "#panel"}, "Saved"),
+	)
+	ass.Equal(
+		`<tr hx-swap-oob="outerHTML:#row-1" class="row"><td>1</td></tr>`,
+		apply(utils.OobSwap{Mode: "outerHTML", Selector: "#row-1"}, ` <tr class="row"><td>1</td></tr>`),
+	)
+	ass.Equal(
+		`<section hx-swap-oob="true"><p>hi</p></section>`,
+		apply(utils.OobSwap{Mode: "true"}, "<section><p>hi</p></section>"),
+	)
+
+	_, err := (&utils.OobSwap{Mode: "outerHTML", Selector: "#x"}).Apply("just text")
+	ass.Error(err)
+}
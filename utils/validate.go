@@ -0,0 +1,245 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Implemented by action bodies that need validation beyond what can be
+// expressed with the `validate` tags. Returning `FieldErrors` reports the
+// errors on the given fields, any other error is reported for the whole
+// form.
+type Validator interface {
+	Validate() error
+}
+
+var patternCache = &sync.Map{}
+
+// Checks the struct fields against the rules in their `validate` tags,
+// then calls the struct's `Validate()` method, if it has one and all the
+// rules passed. The errors are keyed by the form field names, errors
+// concerning the whole form are under an empty key.
+//
+// Supported rules are `required`, `min`, `max` and `len` (the length of
+// strings and slices, or the value of numbers), `email`, `url`, `oneof`
+// (space separated values) and `pattern` (a regular expression, which
+// cannot contain commas), e.g. `validate:"required,min=3,max=64"`.
+func Validate(target interface{}) FieldErrors {
+	errs := FieldErrors{}
+
+	value := reflect.ValueOf(target)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return errs
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Struct {
+		validateStruct(value, "", errs)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	validator, ok := target.(Validator)
+	if !ok {
+		return errs
+	}
+	err := validator.Validate()
+	if err == nil {
+		return errs
+	}
+	var fieldErrs FieldErrors
+	if errors.As(err, &fieldErrs) {
+		return fieldErrs
+	}
+	errs[""] = err.Error()
+	return errs
+}
+
+func validateStruct(structValue reflect.Value, prefix string, errs FieldErrors) {
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		tag := field.Tag.Get("form")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && tag == "" {
+			if fieldValue.Kind() == reflect.Pointer {
+				if fieldValue.IsNil() {
+					continue
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			if fieldValue.Kind() == reflect.Struct {
+				validateStruct(fieldValue, prefix, errs)
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = field.Name
+		}
+		name = prefix + name
+
+		rules := field.Tag.Get("validate")
+		if rules != "" {
+			msg := validateField(fieldValue, rules)
+			if msg != "" {
+				errs[name] = msg
+				continue
+			}
+		}
+
+		nested := fieldValue
+		if nested.Kind() == reflect.Pointer && !nested.IsNil() {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && nested.Type() != timeType {
+			validateStruct(nested, name+".", errs)
+		}
+	}
+}
+
+// Returns the message of the first rule the value doesn't pass
+func validateField(value reflect.Value, rules string) string {
+	isEmpty := value.IsZero()
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Map {
+		isEmpty = value.Len() == 0
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		if name == "required" {
+			if isEmpty {
+				return "is required"
+			}
+			continue
+		}
+		// all the other rules only apply to values that were provided
+		if isEmpty {
+			return ""
+		}
+
+		msg := checkRule(value, name, arg)
+		if msg != "" {
+			return msg
+		}
+	}
+
+	return ""
+}
+
+func checkRule(value reflect.Value, rule string, arg string) string {
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	switch rule {
+	case "min", "max", "len":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Sprintf("has an invalid `%s` rule", rule)
+		}
+		return checkSize(value, rule, limit, arg)
+	case "email":
+		addr, err := mail.ParseAddress(value.String())
+		if err != nil || addr.Address != value.String() {
+			return "must be a valid email address"
+		}
+	case "url":
+		u, err := url.ParseRequestURI(value.String())
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be a valid URL"
+		}
+	case "oneof":
+		options := strings.Fields(arg)
+		str := fmt.Sprint(value.Interface())
+		for _, option := range options {
+			if option == str {
+				return ""
+			}
+		}
+		return "must be one of: " + strings.Join(options, ", ")
+	case "pattern":
+		re, err := compilePattern(arg)
+		if err != nil {
+			return "has an invalid `pattern` rule"
+		}
+		if !re.MatchString(value.String()) {
+			return "has an invalid format"
+		}
+	}
+
+	return ""
+}
+
+func checkSize(value reflect.Value, rule string, limit float64, arg string) string {
+	var size float64
+	unit := ""
+
+	switch value.Kind() {
+	case reflect.String:
+		size = float64(utf8.RuneCountInString(value.String()))
+		unit = " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		size = float64(value.Len())
+		unit = " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		size = value.Float()
+	default:
+		return ""
+	}
+
+	isLength := unit != ""
+	switch {
+	case rule == "min" && size < limit:
+		if isLength {
+			return fmt.Sprintf("must have at least %s%s", arg, unit)
+		}
+		return fmt.Sprintf("must be at least %s", arg)
+	case rule == "max" && size > limit:
+		if isLength {
+			return fmt.Sprintf("must have at most %s%s", arg, unit)
+		}
+		return fmt.Sprintf("must be at most %s", arg)
+	case rule == "len" && size != limit:
+		return fmt.Sprintf("must have exactly %s%s", arg, unit)
+	}
+	return ""
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := patternCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,68 @@
+package utils_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ncpa0/hardwire/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type signupForm struct {
+	Name    string   `form:"name" validate:"required,min=3,max=10"`
+	Email   string   `form:"email" validate:"required,email"`
+	Website string   `form:"website" validate:"url"`
+	Age     int      `form:"age" validate:"min=18"`
+	Plan    string   `form:"plan" validate:"oneof=free pro"`
+	Code    string   `form:"code" validate:"pattern=^[A-Z]{3}$"`
+	Tags    []string `form:"tag" validate:"max=2"`
+	Address struct {
+		City string `form:"city" validate:"required"`
+	} `form:"address"`
+	Password string `form:"password"`
+	Confirm  string `form:"confirm"`
+}
+
+func (f *signupForm) Validate() error {
+	if f.Password != f.Confirm {
+		return utils.FieldErrors{"confirm": "must match the password"}
+	}
+	if f.Name == "admin" {
+		return errors.New("this name is reserved")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	ass := assert.New(t)
+
+	form := &signupForm{
+		Name:    "Jo",
+		Email:   "not-an-email",
+		Website: "example.com",
+		Age:     16,
+		Plan:    "enterprise",
+		Code:    "abc",
+		Tags:    []string{"a", "b", "c"},
+	}
+	ass.Equal(utils.FieldErrors{
+		"name":         "must have at least 3 characters",
+		"email":        "must be a valid email address",
+		"website":      "must be a valid URL",
+		"age":          "must be at least 18",
+		"plan":         "must be one of: free, pro",
+		"code":         "has an invalid format",
+		"tag":          "must have at most 2 items",
+		"address.city": "is required",
+	}, utils.Validate(form))
+
+	form = &signupForm{Name: "John", Email: "john@example.com", Password: "a", Confirm: "b"}
+	form.Address.City = "Berlin"
+	ass.Equal(utils.FieldErrors{"confirm": "must match the password"}, utils.Validate(form))
+
+	form.Confirm = "a"
+	ass.Empty(utils.Validate(form))
+
+	form.Name = "admin"
+	ass.Equal(utils.FieldErrors{"": "this name is reserved"}, utils.Validate(form))
+}
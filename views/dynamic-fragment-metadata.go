@@ -1,24 +1,28 @@
 package views
 
-import (
-	"encoding/json"
-	"os"
-)
-
 type templateMetafile struct {
+	Version      int    `json:"version"`
 	ResourceName string `json:"resourceName"`
 	Hash         string `json:"hash"`
 }
 
-func loadFragmentMetafile(filepath string) (*templateMetafile, error) {
-	file, err := os.Open(filepath)
-	if err != nil {
-		return nil, err
+func (m *templateMetafile) GetVersion() int {
+	return m.Version
+}
+
+func (m *templateMetafile) Validate() error {
+	if m.ResourceName == "" {
+		return fieldError("resourceName", "must not be empty")
+	}
+	if m.Hash == "" {
+		return fieldError("hash", "must not be empty")
 	}
-	defer file.Close()
+	return nil
+}
 
+func loadFragmentMetafile(filepath string) (*templateMetafile, error) {
 	var metafile templateMetafile
-	err = json.NewDecoder(file).Decode(&metafile)
+	err := DecodeMetafile(filepath, &metafile)
 	if err != nil {
 		return nil, err
 	}
@@ -16,6 +16,7 @@ import (
 type DynamicFragmentView struct {
 	id               string
 	template         *template.Template
+	raw              string
 	requiredResource string
 	filepath         string
 	routePathname    string
@@ -74,6 +75,7 @@ func NewDynamicFragmentView(root string, filepath string) (*DynamicFragmentView,
 	return &DynamicFragmentView{
 		id:               metaFile.Hash,
 		template:         templ,
+		raw:              rawHtml,
 		requiredResource: metaFile.ResourceName,
 		filepath:         filepath,
 		routePathname:    routePathname,
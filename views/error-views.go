@@ -0,0 +1,71 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"regexp"
+	"sync"
+)
+
+// Data the error views are rendered with
+type ErrorViewData struct {
+	Code int
+	// Text of the status code, e.g. `Not Found`
+	Status  string
+	Message string
+}
+
+var errorTemplates = &sync.Map{}
+
+var errorViewFile = regexp.MustCompile(`^/(\d{3}|error)\.html$`)
+
+// Error views are only rendered in place of the failed responses, they
+// don't get a route of their own.
+func (v *PageView) IsErrorView() bool {
+	return errorViewFile.MatchString(v.GetFilepath())
+}
+
+// Returns the view of the given status code (e.g. `404.html`), or the
+// generic `error.html` view, from the root of the views dir.
+func GetErrorView(code int) (*PageView, bool) {
+	var fallback *PageView
+	codeFile := fmt.Sprintf("/%d.html", code)
+
+	for view := range pageViewRegistry.views.Iter() {
+		switch view.GetFilepath() {
+		case codeFile:
+			return view, true
+		case "/error.html":
+			fallback = view
+		}
+	}
+
+	return fallback, fallback != nil
+}
+
+// Renders the whole error page. The templates of error views are rendered
+// with the error data instead of the resources, e.g. `{{.Code}}`, the
+// data gets escaped like in any html template.
+func (v *PageView) RenderError(code int, message string) (string, error) {
+	templ, ok := errorTemplates.Load(v)
+	if !ok {
+		parsed, err := template.New(v.filepath + ":error").Parse(v.document.raw)
+		if err != nil {
+			return "", err
+		}
+		templ, _ = errorTemplates.LoadOrStore(v, parsed)
+	}
+
+	var buff bytes.Buffer
+	err := templ.(*template.Template).Execute(&buff, &ErrorViewData{
+		Code:    code,
+		Status:  http.StatusText(code),
+		Message: message,
+	})
+	if err != nil {
+		return "", err
+	}
+	return buff.String(), nil
+}
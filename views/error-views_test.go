@@ -0,0 +1,36 @@
+package views_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ncpa0/hardwire/views"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderErrorView(t *testing.T) {
+	ass := assert.New(t)
+	dir := t.TempDir()
+
+	write := func(name string, content string) {
+		err := os.WriteFile(path.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("404.html", `<html><body><h1>{{.Code}} {{.Status}}</h1><p>{{.Message}}</p></body></html>`)
+	write("404.meta.json", `{"version":1,"isDynamic":false,"resources":[]}`)
+
+	view, err := views.NewPageView(dir, "/404.html")
+	if !ass.NoError(err) {
+		return
+	}
+	ass.True(view.IsErrorView())
+
+	html, err := view.RenderError(404, `<script>alert("x")</script>`)
+	ass.NoError(err)
+	ass.Contains(html, "404 Not Found")
+	ass.NotContains(html, "<script>")
+	ass.Contains(html, "&lt;script&gt;")
+}
@@ -0,0 +1,53 @@
+package views
+
+import (
+	"regexp"
+	"sync"
+)
+
+var fragmentUrlRegex = regexp.MustCompile(`hx-get="/__dyn/([A-Za-z0-9_-]+)`)
+
+var pageFragments = map[*PageView]map[string]bool{}
+var pageFragmentsMutex = &sync.Mutex{}
+
+// Collects the IDs of the fragments loaded by the html, including the ones
+// nested in other fragments.
+func collectFragmentIDs(html string, found map[string]bool) {
+	for _, match := range fragmentUrlRegex.FindAllStringSubmatch(html, -1) {
+		id := match[1]
+		if found[id] {
+			continue
+		}
+		found[id] = true
+		fragment := dynamicFragmentViewRegistry.GetFragmentById(id)
+		if !fragment.IsNil() {
+			collectFragmentIDs(fragment.Get().raw, found)
+		}
+	}
+}
+
+// Tells whether the fragment is rendered anywhere within the page, either
+// directly or within another fragment of the page.
+func (v *PageView) ContainsFragment(fragment *DynamicFragmentView) bool {
+	pageFragmentsMutex.Lock()
+	defer pageFragmentsMutex.Unlock()
+
+	ids, ok := pageFragments[v]
+	if !ok {
+		ids = map[string]bool{}
+		collectFragmentIDs(v.document.raw, ids)
+		pageFragments[v] = ids
+	}
+	return ids[fragment.id]
+}
+
+// Tells whether the fragment belongs to the page of the given route
+// pattern, as sent by the fragments in the `Hardwire-Dynamic-Fragment-Request`
+// header.
+func RouteContainsFragment(route string, fragment *DynamicFragmentView) bool {
+	view := pageViewRegistry.GetViewByRoute(route)
+	if view.IsNil() {
+		return false
+	}
+	return view.Get().ContainsFragment(fragment)
+}
@@ -0,0 +1,305 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/ncpa0/hardwire/utils"
+	. "github.com/ncpa0cpl/ezs"
+)
+
+// Everything needed to turn a freshly rendered island fragment
+// into the markup sent to the client
+type IslandUpdate struct {
+	Island *Island
+	// Parsed html of the rendered fragment
+	Fragment *xmlquery.Node
+	// Swap options requested by the client, without the selector
+	Swap utils.OobSwap
+	// Keys of the list items requested to be patched
+	// (from the `Hardwire-Dynamic-List-Patch` header)
+	ItemKeys *Array[string]
+	// Keys of all the list items currently shown by the client, in
+	// order (from the `Hardwire-Dynamic-List-Order` header), nil if
+	// not sent for this island
+	ClientKeys *Array[string]
+}
+
+// Decides how a rendered island fragment becomes the out-of-band
+// swap markup sent to the client.
+type IslandKind interface {
+	// Name of the kind, as used in the `type` field of the islands
+	// metadata files
+	Name() string
+	// Returns the html containing the `hx-swap-oob` elements that
+	// will update the island on the client
+	BuildSwap(update *IslandUpdate) (string, error)
+}
+
+var islandKinds = NewMap(map[string]IslandKind{
+	"basic": &BasicIslandKind{},
+	"list":  &ListIslandKind{},
+})
+
+// Adds a new island kind, it must be registered before the views
+// are loaded.
+func RegisterIslandKind(kind IslandKind) error {
+	name := kind.Name()
+	if name == "" {
+		return errors.New("island kind name must not be empty")
+	}
+	if islandKinds.Has(name) {
+		return fmt.Errorf("island kind '%s' is already registered", name)
+	}
+	islandKinds.Set(name, kind)
+	return nil
+}
+
+func GetIslandKind(name string) (IslandKind, bool) {
+	return islandKinds.Get(name)
+}
+
+// Replaces the whole island content with the rendered fragment
+type BasicIslandKind struct{}
+
+func (k *BasicIslandKind) Name() string {
+	return "basic"
+}
+
+func (k *BasicIslandKind) BuildSwap(update *IslandUpdate) (string, error) {
+	fragmentNode := xmlquery.FindOne(
+		update.Fragment, "//div[@data-frag-url]",
+	)
+	if fragmentNode == nil {
+		return "", fmt.Errorf("rendered fragment of island %s has no root element", update.Island.ID)
+	}
+
+	swap := update.Swap
+	swap.Selector = "#" + update.Island.ID
+	utils.XmlNodeSetAttribute(
+		fragmentNode,
+		"hx-swap-oob",
+		swap.Build(),
+	)
+
+	return utils.XmlNodeToString(fragmentNode), nil
+}
+
+// Patches only the list items requested by the client, or replaces
+// the whole island like the basic kind if none were requested. When the
+// client sends the keys of all the items it currently shows, the rendered
+// list is diffed against them and new, removed and reordered items are
+// patched as well.
+type ListIslandKind struct{}
+
+func (k *ListIslandKind) Name() string {
+	return "list"
+}
+
+func (k *ListIslandKind) BuildSwap(update *IslandUpdate) (string, error) {
+	if update.ClientKeys != nil {
+		return k.buildDiffSwap(update)
+	}
+
+	if update.ItemKeys == nil || update.ItemKeys.Length() == 0 {
+		return (&BasicIslandKind{}).BuildSwap(update)
+	}
+
+	island := update.Island
+	itemNodes, _ := listItems(update.Fragment)
+	items := NewArray([]string{})
+	for itemKey := range update.ItemKeys.Iter() {
+		if itemNode, ok := itemNodes[itemKey]; ok {
+			items.Push(k.replaceItem(update, itemNode, itemKey))
+		} else {
+			items.Push(k.deleteItem(island, itemKey))
+		}
+	}
+
+	return "\n" + strings.Join(items.ToSlice(), "\n"), nil
+}
+
+func (k *ListIslandKind) buildDiffSwap(update *IslandUpdate) (string, error) {
+	island := update.Island
+	itemNodes, renderedKeys := listItems(update.Fragment)
+
+	patched := []string{}
+	if update.ItemKeys != nil {
+		patched = update.ItemKeys.ToSlice()
+	}
+
+	ops := utils.DiffList(update.ClientKeys.ToSlice(), renderedKeys, patched)
+	appendSelector, canAppend := k.appendSelector(update, itemNodes, renderedKeys)
+	appends := NewArray(ops).Some(func(op utils.ListOp, _ int) bool {
+		return op.Kind == utils.ListOpAppend
+	})
+	if appends && !canAppend {
+		return (&BasicIslandKind{}).BuildSwap(update)
+	}
+
+	// the load more element must stay after the last item, the one shown
+	// by the client is removed and the rendered one (requesting the page
+	// following the re-rendered items) is appended after the items
+	moreSelector := "#" + loadMoreID(island.FragmentID)
+	loadMore := xmlquery.FindOne(update.Fragment, "//div[@class='hw-load-more']")
+	if loadMore != nil && !canAppend {
+		return (&BasicIslandKind{}).BuildSwap(update)
+	}
+
+	items := NewArray([]string{})
+	items.Push(k.deleteElement(moreSelector))
+	for _, op := range ops {
+		switch op.Kind {
+		case utils.ListOpDelete:
+			items.Push(k.deleteItem(island, op.Key))
+		case utils.ListOpReplace:
+			items.Push(k.replaceItem(update, itemNodes[op.Key], op.Key))
+		case utils.ListOpInsertBefore:
+			items.Push(k.insertItem(island, itemNodes[op.Key], "beforebegin", op.Anchor))
+		case utils.ListOpInsertAfter:
+			items.Push(k.insertItem(island, itemNodes[op.Key], "afterend", op.Anchor))
+		case utils.ListOpMoveBefore:
+			// elements cannot be moved with oob swaps, the item is
+			// removed and inserted again at the new position
+			items.Push(k.deleteItem(island, op.Key))
+			items.Push(k.insertItem(island, itemNodes[op.Key], "beforebegin", op.Anchor))
+		case utils.ListOpMoveAfter:
+			items.Push(k.deleteItem(island, op.Key))
+			items.Push(k.insertItem(island, itemNodes[op.Key], "afterend", op.Anchor))
+		case utils.ListOpAppend:
+			items.Push(wrapListItem(itemNodes[op.Key], "beforeend", appendSelector))
+		}
+	}
+	if loadMore != nil {
+		items.Push(wrapListItem(loadMore, "beforeend", appendSelector))
+	}
+
+	return "\n" + strings.Join(items.ToSlice(), "\n"), nil
+}
+
+// Returns the selector of the element the items are appended to. It's
+// only known when the items are direct children of the island, or of an
+// element with an id (e.g. rendered by the list wrapper).
+func (k *ListIslandKind) appendSelector(update *IslandUpdate, itemNodes map[string]*xmlquery.Node, renderedKeys []string) (string, bool) {
+	if len(renderedKeys) == 0 {
+		return "", true
+	}
+	container := itemNodes[renderedKeys[0]].Parent
+	if container == nil {
+		return "", false
+	}
+	if container.SelectAttr("data-frag-url") != "" {
+		return "#" + update.Island.ID, true
+	}
+	if id := container.SelectAttr("id"); id != "" {
+		return "#" + id, true
+	}
+	return "", false
+}
+
+// Returns the items of the island's own list by their keys, and the keys
+// in order. Like on the client, the items of the lists nested in it
+// are skipped.
+func listItems(fragment *xmlquery.Node) (map[string]*xmlquery.Node, []string) {
+	root := xmlquery.FindOne(fragment, "//div[@data-frag-url]")
+
+	itemNodes := map[string]*xmlquery.Node{}
+	keys := []string{}
+	for _, node := range xmlquery.Find(fragment, "//div[@data-item-key]") {
+		if root != nil && closestList(node) != root {
+			continue
+		}
+		key := node.SelectAttr("data-item-key")
+		if _, exists := itemNodes[key]; exists {
+			continue
+		}
+		itemNodes[key] = node
+		keys = append(keys, key)
+	}
+	return itemNodes, keys
+}
+
+// Returns the closest list (or fragment) containing the node
+func closestList(node *xmlquery.Node) *xmlquery.Node {
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		if parent.SelectAttr("data-frag-url") != "" ||
+			slices.Contains(strings.Fields(parent.SelectAttr("class")), "dynamic-list") {
+			return parent
+		}
+	}
+	return nil
+}
+
+// Selects the item of the island's list, but not the items of the
+// lists nested in it
+func listItemSelector(island *Island, itemKey string) string {
+	return fmt.Sprintf(
+		".island_%[1]s .dynamic-list-element[data-item-key='%[2]s']:not(.island_%[1]s .dynamic-list .dynamic-list-element)",
+		island.ID, escapeCSSString(itemKey),
+	)
+}
+
+// Escapes the value to be used within a single quoted css string
+func escapeCSSString(value string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '\\' || r == '\'':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, "\\%x ", r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func (k *ListIslandKind) replaceItem(update *IslandUpdate, itemNode *xmlquery.Node, itemKey string) string {
+	swap := update.Swap
+	swap.Selector = listItemSelector(update.Island, itemKey)
+	utils.XmlNodeSetAttribute(
+		itemNode,
+		"hx-swap-oob",
+		swap.Build(),
+	)
+	return utils.XmlNodeToString(itemNode)
+}
+
+func (k *ListIslandKind) deleteItem(island *Island, itemKey string) string {
+	return k.deleteElement(listItemSelector(island, itemKey))
+}
+
+func (k *ListIslandKind) deleteElement(selector string) string {
+	swap := utils.OobSwap{
+		Mode:     "delete",
+		Selector: selector,
+	}
+	return fmt.Sprintf(
+		"<div hx-swap-oob=\"%s\"></div>",
+		html.EscapeString(swap.Build()),
+	)
+}
+
+// Inserts the item next to the anchor item
+func (k *ListIslandKind) insertItem(island *Island, itemNode *xmlquery.Node, mode string, anchorKey string) string {
+	return wrapListItem(itemNode, mode, listItemSelector(island, anchorKey))
+}
+
+// Oob swaps other than outerHTML insert the children of the oob
+// element, so the item gets wrapped.
+func wrapListItem(itemNode *xmlquery.Node, mode string, selector string) string {
+	swap := utils.OobSwap{
+		Mode:     mode,
+		Selector: selector,
+	}
+	return fmt.Sprintf(
+		"<div hx-swap-oob=\"%s\">%s</div>",
+		html.EscapeString(swap.Build()), utils.XmlNodeToString(itemNode),
+	)
+}
@@ -0,0 +1,137 @@
+package views_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/ncpa0/hardwire/views"
+	. "github.com/ncpa0cpl/ezs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListIslandDiffSwap(t *testing.T) {
+	ass := assert.New(t)
+
+	fragment, err := xmlquery.Parse(strings.NewReader(
+		`<div id="todos" class="dynamic-list" data-frag-url="/__dyn/abc">` +
+			`<div data-item-key="a" class="dynamic-list-element">A</div>` +
+			`<div data-item-key="b" class="dynamic-list-element">B</div>` +
+			`</div>`,
+	))
+	if !ass.NoError(err) {
+		return
+	}
+
+	kind, _ := views.GetIslandKind("list")
+	swap := func(clientKeys []string) string {
+		html, err := kind.BuildSwap(&views.IslandUpdate{
+			Island:     &views.Island{ID: "todos", Type: "list"},
+			Fragment:   fragment,
+			ItemKeys:   NewArray([]string{}),
+			ClientKeys: NewArray(clientKeys),
+		})
+		ass.NoError(err)
+		return html
+	}
+
+	// the client shows no items yet, they're appended to the island
+	html := swap([]string{})
+	ass.Equal(2, strings.Count(html, `hx-swap-oob="beforeend:#todos"`))
+	ass.NotContains(html, "data-frag-url")
+
+	html = swap([]string{"a"})
+	ass.Contains(html, `hx-swap-oob="afterend:.island_todos .dynamic-list-element[data-item-key=&#39;a&#39;]:not(.island_todos .dynamic-list .dynamic-list-element)"`)
+	ass.Contains(html, ">B</div>")
+	ass.NotContains(html, ">A</div>")
+}
+
+func TestListIslandDiffSwapMovesLoadMore(t *testing.T) {
+	ass := assert.New(t)
+
+	fragment, err := xmlquery.Parse(strings.NewReader(
+		`<div id="todos" class="dynamic-list" data-frag-url="/__dyn/abc">` +
+			`<div data-item-key="a" class="dynamic-list-element">A</div>` +
+			`<div data-item-key="b" class="dynamic-list-element">B</div>` +
+			`<div id="__hw_more_abc" class="hw-load-more" hx-get="/__dyn/abc?cursor=b" hx-trigger="revealed" hx-swap="none"></div>` +
+			`</div>`,
+	))
+	if !ass.NoError(err) {
+		return
+	}
+
+	kind, _ := views.GetIslandKind("list")
+	html, err := kind.BuildSwap(&views.IslandUpdate{
+		Island:     &views.Island{ID: "todos", Type: "list", FragmentID: "abc"},
+		Fragment:   fragment,
+		ItemKeys:   NewArray([]string{}),
+		ClientKeys: NewArray([]string{"a"}),
+	})
+	if !ass.NoError(err) {
+		return
+	}
+
+	// the old load more element is removed before the items are
+	// inserted, the rendered one ends up after the last item
+	deleted := strings.Index(html, `hx-swap-oob="delete:#__hw_more_abc"`)
+	inserted := strings.Index(html, ">B</div>")
+	appended := strings.Index(html, `hx-swap-oob="beforeend:#todos"><div id="__hw_more_abc"`)
+	ass.NotEqual(-1, deleted)
+	ass.True(deleted < inserted && inserted < appended)
+}
+
+func TestListIslandSkipsNestedListItems(t *testing.T) {
+	ass := assert.New(t)
+
+	fragment, err := xmlquery.Parse(strings.NewReader(
+		`<div id="todos" class="dynamic-list island_todos" data-frag-url="/__dyn/abc">` +
+			`<div data-item-key="a" class="dynamic-list-element">A` +
+			`<div class="dynamic-list"><div data-item-key="x" class="dynamic-list-element">X</div></div>` +
+			`</div>` +
+			`</div>`,
+	))
+	if !ass.NoError(err) {
+		return
+	}
+
+	kind, _ := views.GetIslandKind("list")
+	html, err := kind.BuildSwap(&views.IslandUpdate{
+		Island:     &views.Island{ID: "todos", Type: "list"},
+		Fragment:   fragment,
+		ItemKeys:   NewArray([]string{}),
+		ClientKeys: NewArray([]string{"a"}),
+	})
+	ass.NoError(err)
+	// the item of the nested list is not inserted into the island's list
+	ass.NotContains(html, "afterend")
+	ass.NotContains(html, "beforeend")
+
+	// keys requested by the client don't match the nested items either
+	html, err = kind.BuildSwap(&views.IslandUpdate{
+		Island:   &views.Island{ID: "todos", Type: "list"},
+		Fragment: fragment,
+		ItemKeys: NewArray([]string{"x"}),
+	})
+	ass.NoError(err)
+	ass.Contains(html, `hx-swap-oob="delete:.island_todos .dynamic-list-element[data-item-key=&#39;x&#39;]`)
+}
+
+func TestListIslandEscapesItemKeys(t *testing.T) {
+	ass := assert.New(t)
+
+	fragment, err := xmlquery.Parse(strings.NewReader(
+		`<div id="todos" class="dynamic-list" data-frag-url="/__dyn/abc"></div>`,
+	))
+	if !ass.NoError(err) {
+		return
+	}
+
+	kind, _ := views.GetIslandKind("list")
+	html, err := kind.BuildSwap(&views.IslandUpdate{
+		Island:   &views.Island{ID: "todos", Type: "list"},
+		Fragment: fragment,
+		ItemKeys: NewArray([]string{`a'],body,[x="`}),
+	})
+	ass.NoError(err)
+	ass.Contains(html, `[data-item-key=&#39;a\&#39;],body,[x=&#34;&#39;]`)
+}
@@ -1,9 +1,8 @@
 package views
 
 import (
-	"encoding/json"
-	"os"
 	"path"
+	"sort"
 	"strings"
 
 	"github.com/ncpa0/hardwire/utils"
@@ -11,9 +10,26 @@ import (
 )
 
 type Island struct {
-	ID         string
-	FragmentID string
-	Type       string
+	Version    int    `json:"version"`
+	ID         string `json:"id"`
+	FragmentID string `json:"fragmentID"`
+	// Key of the resource the island's fragment requires
+	Resource string `json:"resource"`
+	Type     string `json:"type"`
+}
+
+func (island *Island) GetVersion() int {
+	return island.Version
+}
+
+func (island *Island) Validate() error {
+	if island.ID == "" {
+		return fieldError("id", "must not be empty")
+	}
+	if island.FragmentID == "" {
+		return fieldError("fragmentID", "must not be empty")
+	}
+	return validateIslandType(island.Type)
 }
 
 var islandsList = &Array[*Island]{}
@@ -25,18 +41,12 @@ func loadIslands(wd string) error {
 		for _, file := range files {
 			if strings.HasSuffix(file, ".meta.json") {
 				fullPath := path.Join(root, file)
-				file, err := os.Open(fullPath)
-				if err != nil {
-					return err
-				}
-				defer file.Close()
 
 				island := Island{}
-				err = json.NewDecoder(file).Decode(&island)
+				err := DecodeMetafile(fullPath, &island)
 				if err != nil {
 					return err
 				}
-				validateIslandType(island.Type)
 				islandsList.Push(&island)
 			}
 		}
@@ -47,11 +57,14 @@ func loadIslands(wd string) error {
 	return err
 }
 
-func validateIslandType(itype string) {
-	switch itype {
-	case "basic":
-	case "list":
-	default:
-		panic("Invalid island type")
+func validateIslandType(itype string) error {
+	if !islandKinds.Has(itype) {
+		kinds := islandKinds.Keys().ToSlice()
+		sort.Strings(kinds)
+		return fieldError(
+			"type", "must be one of the registered island kinds (%s), got '%s'",
+			strings.Join(kinds, ", "), itype,
+		)
 	}
+	return nil
 }
@@ -0,0 +1,122 @@
+package views
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Version of the metadata files format supported by this runtime. It must
+// match the version written by the html generator into every metadata file.
+const MetadataVersion = 1
+
+// Describes a problem with one of the metadata files produced by the
+// html generator.
+type MetadataError struct {
+	File string
+	// Path of the field the problem is related to, empty if it
+	// concerns the whole file
+	Field   string
+	Message string
+}
+
+func (e *MetadataError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid metadata file %s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("invalid metadata file %s: field `%s` %s", e.File, e.Field, e.Message)
+}
+
+func fieldError(field string, format string, args ...interface{}) *MetadataError {
+	return &MetadataError{
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+type Metafile interface {
+	// Returns the metadata format version the file was written in
+	GetVersion() int
+	// Checks the decoded values, returns a `*MetadataError` describing
+	// the first invalid field found.
+	Validate() error
+}
+
+// Decodes the metadata file into the given target. Unknown fields are
+// rejected, the format version must match the `MetadataVersion` and the
+// decoded values must pass the target's validation.
+func DecodeMetafile(filepath string, target Metafile) error {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(target)
+	if err != nil {
+		metaErr := decodeError(err)
+		metaErr.File = filepath
+		return metaErr
+	}
+
+	version := target.GetVersion()
+	if version == 0 {
+		return &MetadataError{
+			File:  filepath,
+			Field: "version",
+			Message: fmt.Sprintf(
+				"is missing, the file was produced by a generator incompatible with this runtime (metadata version %d)",
+				MetadataVersion,
+			),
+		}
+	}
+	if version != MetadataVersion {
+		return &MetadataError{
+			File:  filepath,
+			Field: "version",
+			Message: fmt.Sprintf(
+				"is %d, but this runtime supports metadata version %d, make sure the html generator and hardwire versions are compatible",
+				version, MetadataVersion,
+			),
+		}
+	}
+
+	err = target.Validate()
+	if err != nil {
+		var metaErr *MetadataError
+		if errors.As(err, &metaErr) {
+			metaErr.File = filepath
+			return metaErr
+		}
+		return &MetadataError{File: filepath, Message: err.Error()}
+	}
+
+	return nil
+}
+
+func decodeError(err error) *MetadataError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fieldError(typeErr.Field, "must be of type %s, got %s", typeErr.Type.String(), typeErr.Value)
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return &MetadataError{
+			Message: fmt.Sprintf("malformed JSON at offset %d: %s", syntaxErr.Offset, syntaxErr.Error()),
+		}
+	}
+
+	const unknownFieldPrefix = "json: unknown field "
+	if strings.HasPrefix(err.Error(), unknownFieldPrefix) {
+		field := strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldPrefix), "\"")
+		return fieldError(field, "is not a known field")
+	}
+
+	return &MetadataError{Message: err.Error()}
+}
@@ -0,0 +1,72 @@
+package views_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ncpa0/hardwire/views"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeMetafile(t *testing.T) {
+	ass := assert.New(t)
+	dir := t.TempDir()
+
+	decode := func(content string) error {
+		filepath := path.Join(dir, "island.meta.json")
+		err := os.WriteFile(filepath, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		island := views.Island{}
+		return views.DecodeMetafile(filepath, &island)
+	}
+
+	ass.NoError(decode(`{"version":1,"id":"a","fragmentID":"b","type":"list"}`))
+	// as written by the generator
+	ass.NoError(decode(`{"id":"a","fragmentID":"b","resource":"todos","type":"basic","version":1}`))
+
+	assertFieldErr := func(err error, field string) {
+		metaErr, ok := err.(*views.MetadataError)
+		if ass.True(ok, "expected a MetadataError, got: %v", err) {
+			ass.Equal(field, metaErr.Field)
+			ass.Equal(path.Join(dir, "island.meta.json"), metaErr.File)
+		}
+	}
+
+	assertFieldErr(decode(`{"id":"a","fragmentID":"b","type":"list"}`), "version")
+	assertFieldErr(decode(`{"version":2,"id":"a","fragmentID":"b","type":"list"}`), "version")
+	assertFieldErr(decode(`{"version":1,"id":"a","fragmentID":"b","type":"list","extra":1}`), "extra")
+	assertFieldErr(decode(`{"version":1,"id":"a","fragmentID":"b","type":"table"}`), "type")
+	assertFieldErr(decode(`{"version":1,"id":1,"fragmentID":"b","type":"list"}`), "id")
+	assertFieldErr(decode(`{"version":1,"fragmentID":"b","type":"list"}`), "id")
+}
+
+func TestPageMetafileRejectsUnknownKeys(t *testing.T) {
+	ass := assert.New(t)
+	dir := t.TempDir()
+
+	err := os.WriteFile(path.Join(dir, "index.html"), []byte(`<html><body></body></html>`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newPage := func(meta string) error {
+		err := os.WriteFile(path.Join(dir, "index.meta.json"), []byte(meta), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = views.NewPageView(dir, "index.html")
+		return err
+	}
+
+	ass.NoError(newPage(`{"version":1,"isDynamic":false,"resources":[]}`))
+	ass.NoError(newPage(`{"version":1,"isDynamic":false,"resources":[],"redirectUrl":"/a","shouldRedirect":true}`))
+
+	err = newPage(`{"version":1,"isDynamic":false,"resources":[],"redirectUrl2":"/a"}`)
+	metaErr, ok := err.(*views.MetadataError)
+	if ass.True(ok, "expected a MetadataError, got: %v", err) {
+		ass.Equal("redirectUrl2", metaErr.Field)
+	}
+}
@@ -1,11 +1,11 @@
 package views
 
 import (
-	"encoding/json"
-	"os"
+	"fmt"
 )
 
 type pageMetafile struct {
+	Version   int  `json:"version"`
 	IsDynamic bool `json:"isDynamic"`
 	Resources [](struct {
 		Key string `json:"key"`
@@ -15,15 +15,30 @@ type pageMetafile struct {
 	ShouldRedirect bool   `json:"shouldRedirect"`
 }
 
-func loadPageMetafile(filepath string) (*pageMetafile, error) {
-	file, err := os.Open(filepath)
-	if err != nil {
-		return nil, err
+func (m *pageMetafile) GetVersion() int {
+	return m.Version
+}
+
+func (m *pageMetafile) Validate() error {
+	for i, res := range m.Resources {
+		if res.Key == "" {
+			return fieldError(fmt.Sprintf("resources[%d].key", i), "must not be empty")
+		}
+		if res.Res == "" {
+			return fieldError(fmt.Sprintf("resources[%d].res", i), "must not be empty")
+		}
 	}
-	defer file.Close()
 
+	if m.ShouldRedirect && m.RedirectURL == "" {
+		return fieldError("redirectUrl", "must not be empty when `shouldRedirect` is set")
+	}
+
+	return nil
+}
+
+func loadPageMetafile(filepath string) (*pageMetafile, error) {
 	var metafile pageMetafile
-	err = json.NewDecoder(file).Decode(&metafile)
+	err := DecodeMetafile(filepath, &metafile)
 	if err != nil {
 		return nil, err
 	}
@@ -21,7 +21,19 @@ func (vr *PageViewRegistry) Register(view *PageView) {
 
 func (vr *PageViewRegistry) GetView(routePathname string) *utils.Option[PageView] {
 	for view := range vr.views.Iter() {
-		if view.MatchesRoute(routePathname) {
+		if !view.IsErrorView() && view.MatchesRoute(routePathname) {
+			return utils.NewOption(view)
+		}
+	}
+
+	return utils.Empty[PageView]()
+}
+
+// Returns the view registered under the exact route pattern, e.g.
+// `/users/:id`, unlike `GetView` which matches a concrete path.
+func (vr *PageViewRegistry) GetViewByRoute(route string) *utils.Option[PageView] {
+	for view := range vr.views.Iter() {
+		if !view.IsErrorView() && view.routePathname == route {
 			return utils.NewOption(view)
 		}
 	}
@@ -261,6 +261,20 @@ func (v *PageView) QuerySelectorAll(selector string) []*NodeProxy {
 	return result
 }
 
+// Renders the element with the given template data, instead of the
+// resources of the page. Elements of static pages are returned as is.
+func (node *NodeProxy) Build(data interface{}) (string, error) {
+	if node.template == nil {
+		return node.raw, nil
+	}
+	var buff bytes.Buffer
+	err := node.template.Execute(&buff, data)
+	if err != nil {
+		return "", err
+	}
+	return buff.String(), nil
+}
+
 type RenderedView struct {
 	Html string
 	Etag string
@@ -0,0 +1,120 @@
+package views
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/ncpa0/hardwire/configuration"
+	"github.com/ncpa0/hardwire/utils"
+	. "github.com/ncpa0cpl/ezs"
+)
+
+func (v *DynamicFragmentView) GetID() string {
+	return v.id
+}
+
+func loadMoreID(fragmentID string) string {
+	return "__hw_more_" + fragmentID
+}
+
+// Returns the element requesting the page of the given cursor, once it's
+// revealed or clicked (depending on the configured trigger). The returned
+// items are inserted right before it. The query of the current request
+// (e.g. the `limit`) is kept, only the cursor is replaced.
+func LoadMoreElement(fragment *DynamicFragmentView, query url.Values, nextCursor string) string {
+	conf := configuration.Current.Pagination
+
+	moreQuery := url.Values{}
+	for key, values := range query {
+		moreQuery[key] = values
+	}
+	moreQuery.Set("cursor", nextCursor)
+	moreUrl := fragment.GetRoutePathname() + "?" + moreQuery.Encode()
+
+	trigger := "revealed"
+	content := ""
+	if conf.Trigger == "click" {
+		trigger = "click"
+		content = fmt.Sprintf("<button type=\"button\">%s</button>", html.EscapeString(conf.LoadMoreLabel))
+	}
+
+	return fmt.Sprintf(
+		"<div id=\"%s\" class=\"hw-load-more\" hx-get=\"%s\" hx-trigger=\"%s\" hx-swap=\"none\">%s</div>",
+		loadMoreID(fragment.id), html.EscapeString(moreUrl), trigger, content,
+	)
+}
+
+func parseNode(rawHtml string) (*xmlquery.Node, error) {
+	doc, err := xmlquery.Parse(strings.NewReader(rawHtml))
+	if err != nil {
+		return nil, err
+	}
+	node := doc.FirstChild
+	for node != nil && node.Type != xmlquery.ElementNode {
+		node = node.NextSibling
+	}
+	if node == nil {
+		return nil, fmt.Errorf("no element found in: %s", rawHtml)
+	}
+	xmlquery.RemoveFromTree(node)
+	return node, nil
+}
+
+// Adds the load more element after the last item of the rendered list
+// fragment. Fragments without any items are returned unchanged.
+func InjectLoadMore(fragment *DynamicFragmentView, fragmentHtml string, query url.Values, nextCursor string) (string, error) {
+	doc, err := xmlquery.Parse(strings.NewReader(fragmentHtml))
+	if err != nil {
+		return "", err
+	}
+
+	items := xmlquery.Find(doc, "//div[@data-item-key]")
+	if len(items) == 0 {
+		return fragmentHtml, nil
+	}
+
+	loadMore, err := parseNode(LoadMoreElement(fragment, query, nextCursor))
+	if err != nil {
+		return "", err
+	}
+	xmlquery.AddChild(items[len(items)-1].Parent, loadMore)
+
+	root := xmlquery.FindOne(doc, "//div[@data-frag-url]")
+	if root == nil {
+		return "", fmt.Errorf("rendered fragment %s has no root element", fragment.GetRoutePathname())
+	}
+	return utils.XmlNodeToString(root), nil
+}
+
+// Builds the response to a load more request, the items of the rendered
+// page are inserted before the load more element, which then gets replaced
+// with one requesting the following page, or removed after the last page.
+func BuildLoadMoreSwap(fragment *DynamicFragmentView, fragmentHtml string, query url.Values, nextCursor string) (string, error) {
+	doc, err := xmlquery.Parse(strings.NewReader(fragmentHtml))
+	if err != nil {
+		return "", err
+	}
+
+	selector := "#" + loadMoreID(fragment.id)
+	parts := NewArray([]string{})
+	for _, item := range xmlquery.Find(doc, "//div[@data-item-key]") {
+		parts.Push(wrapListItem(item, "beforebegin", selector))
+	}
+
+	if nextCursor == "" {
+		swap := utils.OobSwap{Mode: "delete", Selector: selector}
+		parts.Push(fmt.Sprintf("<div hx-swap-oob=\"%s\"></div>", swap.Build()))
+	} else {
+		loadMore, err := parseNode(LoadMoreElement(fragment, query, nextCursor))
+		if err != nil {
+			return "", err
+		}
+		utils.XmlNodeSetAttribute(loadMore, "hx-swap-oob", "true")
+		parts.Push(utils.XmlNodeToString(loadMore))
+	}
+
+	return strings.Join(parts.ToSlice(), "\n"), nil
+}
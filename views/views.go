@@ -2,7 +2,6 @@ package views
 
 import (
 	"fmt"
-	"os"
 	"path"
 	"strings"
 
@@ -20,31 +19,29 @@ var pageViewRegistry = NewViewRegistry()
 var dynamicFragmentViewRegistry = NewDynamicFragmentViewRegistry()
 
 func LoadViews(wd string) error {
-	htmlDir := config.Current.HtmlDir
-	if !path.IsAbs(htmlDir) {
-		htmlDir = path.Join(wd, htmlDir)
-	}
-
-	if !config.Current.NoBuild {
-		if config.Current.CleanBuild {
-			err := os.RemoveAll(htmlDir)
-			if err != nil {
-				return err
-			}
+	if config.Current.Prebuilt {
+		err := templatebuilder.VerifyProjectArtifacts(wd)
+		if err != nil {
+			return fmt.Errorf("unable to load the prebuilt views: %w", err)
 		}
-
-		err := templatebuilder.BuildPages(
-			config.Current.Entrypoint,
-			htmlDir,
-			config.Current.StaticDir,
-			config.Current.StaticURL,
-		)
-
+	} else if !config.Current.NoBuild {
+		_, err := templatebuilder.BuildArtifacts(wd)
 		if err != nil {
 			return err
 		}
 	}
 
+	return LoadBuiltViews(wd)
+}
+
+// Loads the views already present in the html directory, without
+// running the html generator.
+func LoadBuiltViews(wd string) error {
+	htmlDir := config.Current.HtmlDir
+	if !path.IsAbs(htmlDir) {
+		htmlDir = path.Join(wd, htmlDir)
+	}
+
 	if config.Current.DebugMode {
 		fmt.Printf("Loading view from %s\n", htmlDir)
 	}